@@ -0,0 +1,133 @@
+package gedis
+
+import (
+	"strconv"
+
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+const (
+	BitAnd = `AND`
+	BitOr  = `OR`
+	BitXor = `XOR`
+	BitNot = `NOT`
+)
+
+// BitFieldType 位域类型，例如i8、u16
+type BitFieldType string
+
+// Signed 有符号位域类型，bits最大64
+func Signed(bits int) BitFieldType {
+	return BitFieldType("i" + strconv.Itoa(bits))
+}
+
+// Unsigned 无符号位域类型，bits最大63
+func Unsigned(bits int) BitFieldType {
+	return BitFieldType("u" + strconv.Itoa(bits))
+}
+
+// BitFieldOverflow 溢出处理方式，对之后的SET与INCRBY生效
+type BitFieldOverflow string
+
+const (
+	OverflowWrap BitFieldOverflow = `WRAP`
+	OverflowSat  BitFieldOverflow = `SAT`
+	OverflowFail BitFieldOverflow = `FAIL`
+)
+
+// BitFieldArgs BITFIELD子命令
+type BitFieldArgs struct {
+	args []interface{}
+}
+
+// NewBitFieldArgs 实例化BitFieldArgs
+func NewBitFieldArgs() *BitFieldArgs {
+	return &BitFieldArgs{args: make([]interface{}, 0, 8)}
+}
+
+// Get 读取offset处的位域
+func (bf *BitFieldArgs) Get(t BitFieldType, offset int) *BitFieldArgs {
+	bf.args = append(bf.args, "GET", string(t), offset)
+	return bf
+}
+
+// Set 设置offset处的位域，返回旧值
+func (bf *BitFieldArgs) Set(t BitFieldType, offset int, value int64) *BitFieldArgs {
+	bf.args = append(bf.args, "SET", string(t), offset, value)
+	return bf
+}
+
+// IncrBy 增加offset处的位域，返回新值
+func (bf *BitFieldArgs) IncrBy(t BitFieldType, offset int, increment int64) *BitFieldArgs {
+	bf.args = append(bf.args, "INCRBY", string(t), offset, increment)
+	return bf
+}
+
+// Overflow 设置之后的SET与INCRBY的溢出处理方式
+func (bf *BitFieldArgs) Overflow(mode BitFieldOverflow) *BitFieldArgs {
+	bf.args = append(bf.args, "OVERFLOW", string(mode))
+	return bf
+}
+
+// params bf为nil时视为没有子命令
+func (bf *BitFieldArgs) params(key string) []interface{} {
+	if bf == nil {
+		return []interface{}{key}
+	}
+
+	params := make([]interface{}, 0, len(bf.args)+1)
+	params = append(params, key)
+	return append(params, bf.args...)
+}
+
+//region 1.12 Bitmap
+
+// BitOp redis命令，operation为BitAnd、BitOr、BitXor或BitNot，返回destKey的长度
+func (mp *myPool) BitOp(operation, destKey string, keys ...string) (strLength int, err error) {
+	return redigo.Int(mp.Do("BITOP", bitOpArgs(operation, destKey, keys)...))
+}
+
+// BitPos redis命令，args为可选的start、end，不存在时返回-1
+func (mp *myPool) BitPos(key string, bit int8, args ...interface{}) (position int, err error) {
+	return redigo.Int(mp.Do("BITPOS", bitPosArgs(key, bit, args)...))
+}
+
+// BitField redis命令，OVERFLOW FAIL溢出时对应位置为nil，bf为nil时不执行任何子命令
+func (mp *myPool) BitField(key string, bf *BitFieldArgs) (values []*int64, err error) {
+	return Int64Ptrs(mp.Do("BITFIELD", bf.params(key)...))
+}
+
+//endregion
+
+func bitOpArgs(operation, destKey string, keys []string) []interface{} {
+	args := make([]interface{}, 0, len(keys)+2)
+	args = append(args, operation, destKey)
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	return args
+}
+
+func bitPosArgs(key string, bit int8, args []interface{}) []interface{} {
+	params := make([]interface{}, 0, len(args)+2)
+	params = append(params, key, bit)
+	return append(params, args...)
+}
+
+// BitOp redis命令
+func (m *multi) BitOp(operation, destKey string, keys ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "BITOP", args: bitOpArgs(operation, destKey, keys)})
+	return m
+}
+
+// BitPos redis命令
+func (m *multi) BitPos(key string, bit int8, args ...interface{}) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "BITPOS", args: bitPosArgs(key, bit, args)})
+	return m
+}
+
+// BitField redis命令
+func (m *multi) BitField(key string, bf *BitFieldArgs) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "BITFIELD", args: bf.params(key)})
+	return m
+}
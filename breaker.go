@@ -0,0 +1,239 @@
+package gedis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultBreakerWindow      = 10000
+	defaultBreakerMinRequests = 20
+	defaultBreakerOpenTimeout = 5000
+)
+
+var (
+	ErrBreakerOpen = NewError(`breaker: circuit open`)
+)
+
+type BreakerState uint8
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (bs BreakerState) String() string {
+	switch bs {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerOption 熔断配置，ErrorRate与SlowRate均为0时不开启
+type BreakerOption struct {
+	//统计窗口，单位ms，默认10000
+	Window int `yaml:"window" json:"window"`
+	//窗口内请求数达到MinRequests才会熔断，默认20
+	MinRequests int `yaml:"minRequests" json:"minRequests"`
+	//失败率阈值，取值(0,1]
+	ErrorRate float64 `yaml:"errorRate" json:"errorRate"`
+	//慢调用阈值，单位ms
+	SlowThreshold int `yaml:"slowThreshold" json:"slowThreshold"`
+	//慢调用比例阈值，取值(0,1]
+	SlowRate float64 `yaml:"slowRate" json:"slowRate"`
+	//熔断持续时长，之后进入半开状态，单位ms，默认5000
+	OpenTimeout int `yaml:"openTimeout" json:"openTimeout"`
+	//半开状态放行的探测请求数，全部成功后关闭熔断，默认1
+	HalfOpenRequests int `yaml:"halfOpenRequests" json:"halfOpenRequests"`
+}
+
+func (bo *BreakerOption) enabled() bool {
+	return bo.ErrorRate > 0 || (bo.SlowRate > 0 && bo.SlowThreshold > 0)
+}
+
+// breaker 熔断器，同一redis的虚拟节点共享
+type breaker struct {
+	option        BreakerOption
+	window        time.Duration
+	slowThreshold time.Duration
+	openTimeout   time.Duration
+	id            string
+	logger        *poolLogger
+
+	mu          sync.Mutex
+	state       BreakerState
+	windowStart time.Time
+	openedAt    time.Time
+	total       int
+	failures    int
+	slows       int
+	probes      int
+	successes   int
+}
+
+func newBreaker(option Option, logger *poolLogger) *breaker {
+	bo := option.Breaker
+	if !bo.enabled() {
+		return nil
+	}
+
+	if bo.Window < 1 {
+		bo.Window = defaultBreakerWindow
+	}
+
+	if bo.MinRequests < 1 {
+		bo.MinRequests = defaultBreakerMinRequests
+	}
+
+	if bo.OpenTimeout < 1 {
+		bo.OpenTimeout = defaultBreakerOpenTimeout
+	}
+
+	if bo.HalfOpenRequests < 1 {
+		bo.HalfOpenRequests = 1
+	}
+
+	return &breaker{
+		option:        bo,
+		window:        time.Millisecond * time.Duration(bo.Window),
+		slowThreshold: time.Millisecond * time.Duration(bo.SlowThreshold),
+		openTimeout:   time.Millisecond * time.Duration(bo.OpenTimeout),
+		id:            option.id(),
+		logger:        logger,
+		windowStart:   time.Now(),
+	}
+}
+
+// current 当前状态，熔断超时后视为半开
+func (b *breaker) current() BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.openTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// allow 是否放行本次请求
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.option.HalfOpenRequests {
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+// report 上报执行结果
+func (b *breaker) report(err error, duration time.Duration) {
+	if b == nil {
+		return
+	}
+
+	failed := isBreakerFailure(err)
+	slow := b.slowThreshold > 0 && duration >= b.slowThreshold
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerHalfOpen:
+		if failed || slow {
+			b.setState(BreakerOpen)
+			return
+		}
+
+		b.successes++
+		if b.successes >= b.option.HalfOpenRequests {
+			b.setState(BreakerClosed)
+		}
+	case BreakerClosed:
+		if now := time.Now(); now.Sub(b.windowStart) >= b.window {
+			b.windowStart = now
+			b.total, b.failures, b.slows = 0, 0, 0
+		}
+
+		b.total++
+		if failed {
+			b.failures++
+		}
+		if slow {
+			b.slows++
+		}
+
+		if b.total < b.option.MinRequests {
+			return
+		}
+
+		if (b.option.ErrorRate > 0 && float64(b.failures) >= b.option.ErrorRate*float64(b.total)) ||
+			(b.option.SlowRate > 0 && float64(b.slows) >= b.option.SlowRate*float64(b.total)) {
+			b.setState(BreakerOpen)
+		}
+	}
+}
+
+func (b *breaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+
+	b.logger.log(zapcore.WarnLevel, msgBreakerChanged,
+		zaplogger.Addr(b.id),
+		zaplogger.String("From", b.state.String()),
+		zaplogger.String("To", state.String()),
+	)
+
+	b.state = state
+	b.probes, b.successes = 0, 0
+	b.total, b.failures, b.slows = 0, 0, 0
+	b.windowStart = time.Now()
+	if state == BreakerOpen {
+		b.openedAt = b.windowStart
+	}
+}
+
+// isBreakerFailure 只有网络错误、超时与连接池耗尽计为失败，WRONGTYPE等业务错误不计
+func isBreakerFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	return IsRetryable(err) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, redigo.ErrPoolExhausted)
+}
+
+// BreakerState 熔断状态，未配置熔断时始终为BreakerClosed
+func (mp *myPool) BreakerState() BreakerState {
+	return mp.breaker.current()
+}
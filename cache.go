@@ -1,12 +1,14 @@
 package gedis
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	redigo "github.com/garyburd/redigo/redis"
 	"github.com/grpc-boot/base"
 	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/zap/zapcore"
 )
 
 const (
@@ -33,7 +35,7 @@ func (i *Item) Hit(timeoutSecond int64, current int64) bool {
 func (mp *myPool) updateCache(key string, item *Item, current int64, handler Handler) (err error) {
 	value, err := handler()
 	if err != nil {
-		Error("cache exec handler failed",
+		mp.logger.log(zapcore.WarnLevel, msgCacheFailed,
 			zaplogger.Key(key),
 			zaplogger.Error(err),
 		)
@@ -82,6 +84,11 @@ func (mp *myPool) CacheGet(key string, current, timeoutSecond int64, handler Han
 	return item.Value, err
 }
 
+// CacheGetCtx 通用缓存，redis操作受ctx的超时与取消控制
+func (mp *myPool) CacheGetCtx(ctx context.Context, key string, current, timeoutSecond int64, handler Handler) (value []byte, err error) {
+	return mp.bind(ctx).CacheGet(key, current, timeoutSecond, handler)
+}
+
 // CacheGetItem 通用缓存
 func (mp *myPool) CacheGetItem(key string, current, timeoutSecond int64, handler Handler) (item Item, err error) {
 	var redisValue map[string][]byte
@@ -94,6 +101,7 @@ func (mp *myPool) CacheGetItem(key string, current, timeoutSecond int64, handler
 
 	//redis中没有数据
 	if redisValue == nil {
+		mp.counter.cacheMiss.Inc()
 		err = mp.updateCache(key, &item, current, handler)
 		return item, err
 	}
@@ -113,6 +121,7 @@ func (mp *myPool) CacheGetItem(key string, current, timeoutSecond int64, handler
 	}
 
 	if item.UpdatedAt == 0 || !ok {
+		mp.counter.cacheMiss.Inc()
 		err = mp.updateCache(key, &item, current, handler)
 		return item, err
 	}
@@ -121,6 +130,7 @@ func (mp *myPool) CacheGetItem(key string, current, timeoutSecond int64, handler
 
 	//缓存有效
 	if item.Hit(timeoutSecond, current) {
+		mp.counter.cacheHit.Inc()
 		return item, err
 	}
 
@@ -129,10 +139,12 @@ func (mp *myPool) CacheGetItem(key string, current, timeoutSecond int64, handler
 	token, _ := mp.Acquire(key, lockTimeoutSecond)
 	//未获得锁
 	if token == 0 {
+		mp.counter.cacheHit.Inc()
 		return item, nil
 	}
 
 	// 获得锁
+	mp.counter.cacheRefresh.Inc()
 	err = mp.updateCache(key, &item, current, handler)
 	if err == nil {
 		_, _ = mp.Release(key, token)
@@ -140,3 +152,8 @@ func (mp *myPool) CacheGetItem(key string, current, timeoutSecond int64, handler
 
 	return item, err
 }
+
+// CacheGetItemCtx 通用缓存，redis操作受ctx的超时与取消控制
+func (mp *myPool) CacheGetItemCtx(ctx context.Context, key string, current, timeoutSecond int64, handler Handler) (item Item, err error) {
+	return mp.bind(ctx).CacheGetItem(key, current, timeoutSecond, handler)
+}
@@ -0,0 +1,753 @@
+package gedis
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/base"
+	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	slotCount           = 16384
+	defaultMaxRedirects = 8
+)
+
+var (
+	ErrNoClusterNode    = NewError(`cluster: no node available`)
+	ErrTooManyRedirects = NewError(`cluster: too many redirects`)
+	ErrCrossSlot        = NewError(`cluster: keys in transaction don't hash to the same slot`)
+	ErrSlotMigrating    = NewError(`cluster: transaction slot is migrating, try again later`)
+	ErrClusterClosed    = NewError(`cluster: closed`)
+)
+
+// ClusterOption 集群配置，Option中的Host与Port被忽略
+type ClusterOption struct {
+	Option Option `yaml:"option" json:"option"`
+	//种子节点，格式为host:port
+	Addrs []string `yaml:"addrs" json:"addrs"`
+	//MOVED/ASK最大重定向次数
+	MaxRedirects int `yaml:"maxRedirects" json:"maxRedirects"`
+}
+
+type Cluster interface {
+	Pool
+
+	// ReloadSlots 重新加载slot分布
+	ReloadSlots() (err error)
+	// Node 获取key所在的节点
+	Node(key string) (p Pool, err error)
+	// RangeNode 遍历master节点
+	RangeNode(handler func(addr string, p Pool) (handled bool))
+}
+
+// cluster 所有Pool方法经由myPool分发到clusterClient，按slot路由到各节点
+type cluster struct {
+	*myPool
+}
+
+type clusterClient struct {
+	option    ClusterOption
+	mu        sync.RWMutex
+	slots     []*myPool
+	nodes     map[string]*myPool
+	reloading int32
+	closed    bool
+	logger    *poolLogger
+	//异步刷新slot的goroutine，close时等待其退出
+	wg sync.WaitGroup
+}
+
+// NewCluster 实例化Cluster
+func NewCluster(option ClusterOption) (c Cluster, err error) {
+	if len(option.Addrs) < 1 {
+		return nil, ErrOptionEmpty
+	}
+
+	if option.MaxRedirects < 1 {
+		option.MaxRedirects = defaultMaxRedirects
+	}
+
+	// 节点地址由slot分布决定，URL只用于其他配置
+	if option.Option, err = option.Option.resolveURL(); err != nil {
+		return nil, err
+	}
+
+	cc := &clusterClient{
+		option: option,
+		slots:  make([]*myPool, slotCount),
+		nodes:  make(map[string]*myPool),
+		logger: newPoolLogger(option.Option),
+	}
+
+	if err = cc.reload(); err != nil {
+		_ = cc.close()
+		return nil, err
+	}
+
+	return &cluster{
+		myPool: &myPool{
+			id:          []byte("cluster:" + strings.Join(option.Addrs, ",")),
+			readTimeout: time.Millisecond * time.Duration(option.Option.ReadTimeout),
+			hooks:       &hooks{},
+			counter:     &counter{},
+			logger:      cc.logger,
+			cluster:     cc,
+		},
+	}, nil
+}
+
+func (c *cluster) HashCode() uint32 {
+	return crc32.ChecksumIEEE(c.id)
+}
+
+func (c *cluster) ActiveCount() (num int) {
+	return c.Stats().ActiveCount
+}
+
+func (c *cluster) IdleCount() (num int) {
+	return c.Stats().IdleCount
+}
+
+func (c *cluster) Stats() (stats redigo.PoolStats) {
+	c.cluster.rangeNode(func(addr string, node *myPool) bool {
+		s := node.Stats()
+		stats.ActiveCount += s.ActiveCount
+		stats.IdleCount += s.IdleCount
+		return false
+	})
+	return
+}
+
+// Counter 缓存与限流计数取自集群，连接计数为各节点之和
+func (c *cluster) Counter() (counter Counter) {
+	counter = c.myPool.Counter()
+	c.cluster.rangeNode(func(addr string, node *myPool) bool {
+		nc := node.Counter()
+		counter.ConnGet += nc.ConnGet
+		counter.ConnWait += nc.ConnWait
+		return false
+	})
+	return
+}
+
+func (c *cluster) Close() (err error) {
+	return c.cluster.close()
+}
+
+func (c *cluster) WithContext(ctx context.Context) Pool {
+	return &cluster{myPool: c.bind(ctx)}
+}
+
+func (c *cluster) ReloadSlots() (err error) {
+	return c.cluster.reload()
+}
+
+func (c *cluster) Node(key string) (p Pool, err error) {
+	node := c.cluster.nodeBySlot(Slot(key))
+	if node == nil {
+		return nil, ErrNoClusterNode
+	}
+	return node, nil
+}
+
+func (c *cluster) RangeNode(handler func(addr string, p Pool) (handled bool)) {
+	c.cluster.rangeNode(func(addr string, node *myPool) bool {
+		return handler(addr, node)
+	})
+}
+
+//region slot
+
+// Slot 计算key所在的slot，key中包含{hashtag}时只计算hashtag
+func Slot(key string) int {
+	if start := strings.IndexByte(key, '{'); start > -1 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	return int(crc16(key) % slotCount)
+}
+
+var crc16Table = func() (table [256]uint16) {
+	for i := range table {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return
+}()
+
+// crc16 CRC16-CCITT(XMODEM)，与redis集群一致
+func crc16(key string) (crc uint16) {
+	for i := 0; i < len(key); i++ {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^key[i]]
+	}
+	return
+}
+
+func keyString(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return base.Bytes2String(k)
+	default:
+		return fmt.Sprint(k)
+	}
+}
+
+// commandSlot 获取命令访问的key所在的slot，无key的命令返回-1
+func commandSlot(cmd string, args []interface{}) int {
+	switch strings.ToUpper(cmd) {
+	case "PING", "ECHO", "INFO", "TIME", "DBSIZE", "CLIENT", "CONFIG", "CLUSTER", "COMMAND",
+		"KEYS", "SCAN", "RANDOMKEY", "FLUSHDB", "FLUSHALL", "PUBLISH", "PUBSUB", "SCRIPT", "SLOWLOG":
+		return -1
+	case "EVAL", "EVALSHA":
+		if len(args) > 2 {
+			if num, _ := strconv.Atoi(keyString(args[1])); num > 0 {
+				return Slot(keyString(args[2]))
+			}
+		}
+		return -1
+	case "XREAD", "XREADGROUP":
+		for index, arg := range args {
+			if strings.EqualFold(keyString(arg), "STREAMS") && index+1 < len(args) {
+				return Slot(keyString(args[index+1]))
+			}
+		}
+		return -1
+	case "BITOP", "OBJECT", "XGROUP", "XINFO", "MEMORY", "ZDIFF", "ZUNION", "ZINTER":
+		if len(args) > 1 {
+			return Slot(keyString(args[1]))
+		}
+		return -1
+	}
+
+	if len(args) < 1 {
+		return -1
+	}
+	return Slot(keyString(args[0]))
+}
+
+// parseRedirect 解析MOVED与ASK错误，格式为: MOVED 3999 127.0.0.1:6381
+func parseRedirect(err error) (addr string, ask bool, ok bool) {
+	re, isRedisErr := err.(redigo.Error)
+	if !isRedisErr {
+		return "", false, false
+	}
+
+	parts := strings.Fields(string(re))
+	if len(parts) != 3 {
+		return "", false, false
+	}
+
+	switch parts[0] {
+	case "MOVED":
+		return parts[2], false, true
+	case "ASK":
+		return parts[2], true, true
+	}
+	return "", false, false
+}
+
+//endregion
+
+//region nodes
+
+func (cc *clusterClient) reload() (err error) {
+	addrs := append([]string{}, cc.option.Addrs...)
+	cc.rangeNode(func(addr string, node *myPool) bool {
+		addrs = append(addrs, addr)
+		return false
+	})
+
+	err = ErrNoClusterNode
+	for _, addr := range addrs {
+		if err = cc.loadSlots(addr); err == nil {
+			return nil
+		}
+
+		cc.logger.log(zapcore.ErrorLevel, msgClusterLoadFailed,
+			zaplogger.Addr(addr),
+			zaplogger.Error(err),
+		)
+	}
+
+	return err
+}
+
+// reloadAsync 收到MOVED后异步刷新slot分布，同一时刻只有一个刷新在执行
+func (cc *clusterClient) reloadAsync() {
+	if !atomic.CompareAndSwapInt32(&cc.reloading, 0, 1) {
+		return
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.closed {
+		atomic.StoreInt32(&cc.reloading, 0)
+		return
+	}
+
+	cc.wg.Add(1)
+	go func() {
+		defer cc.wg.Done()
+		defer atomic.StoreInt32(&cc.reloading, 0)
+		_ = cc.reload()
+	}()
+}
+
+func (cc *clusterClient) loadSlots(seed string) (err error) {
+	node, err := cc.nodeByAddr(seed)
+	if err != nil {
+		return err
+	}
+
+	values, err := redigo.Values(node.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return err
+	}
+
+	seedHost, _, _ := net.SplitHostPort(seed)
+	slots := make([]*myPool, slotCount)
+	used := make(map[string]bool)
+
+	for _, value := range values {
+		item, er := redigo.Values(value, nil)
+		if er != nil || len(item) < 3 {
+			continue
+		}
+
+		start, _ := redigo.Int(item[0], nil)
+		end, _ := redigo.Int(item[1], nil)
+		master, _ := redigo.Values(item[2], nil)
+		if len(master) < 2 {
+			continue
+		}
+
+		host, _ := redigo.String(master[0], nil)
+		port, _ := redigo.Int(master[1], nil)
+		if host == "" {
+			host = seedHost
+		}
+
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		if node, err = cc.nodeByAddr(addr); err != nil {
+			return err
+		}
+
+		used[addr] = true
+		for slot := start; slot <= end && slot < slotCount; slot++ {
+			slots[slot] = node
+		}
+	}
+
+	cc.mu.Lock()
+	cc.slots = slots
+	removed := make([]*myPool, 0)
+	for addr, node := range cc.nodes {
+		if !used[addr] {
+			delete(cc.nodes, addr)
+			removed = append(removed, node)
+		}
+	}
+	cc.mu.Unlock()
+
+	for _, node := range removed {
+		_ = node.Close()
+	}
+	return nil
+}
+
+func (cc *clusterClient) nodeByAddr(addr string) (node *myPool, err error) {
+	cc.mu.RLock()
+	node, ok := cc.nodes[addr]
+	cc.mu.RUnlock()
+	if ok {
+		return node, nil
+	}
+
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.closed {
+		return nil, ErrClusterClosed
+	}
+
+	if node, ok = cc.nodes[addr]; ok {
+		return node, nil
+	}
+
+	option := cc.option.Option
+	option.Host = host
+	option.Port = port
+	node = NewPool(option).(*myPool)
+	cc.nodes[addr] = node
+	return node, nil
+}
+
+// nodeBySlot 获取slot所在的节点，slot为-1或未分配时随机返回一个节点
+func (cc *clusterClient) nodeBySlot(slot int) (node *myPool) {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+
+	if slot > -1 && slot < len(cc.slots) && cc.slots[slot] != nil {
+		return cc.slots[slot]
+	}
+
+	if len(cc.nodes) == 0 {
+		return nil
+	}
+
+	index := rand.Intn(len(cc.nodes))
+	for _, node = range cc.nodes {
+		if index == 0 {
+			break
+		}
+		index--
+	}
+	return node
+}
+
+func (cc *clusterClient) rangeNode(handler func(addr string, node *myPool) (handled bool)) {
+	cc.mu.RLock()
+	nodes := make(map[string]*myPool, len(cc.nodes))
+	for addr, node := range cc.nodes {
+		nodes[addr] = node
+	}
+	cc.mu.RUnlock()
+
+	for addr, node := range nodes {
+		if handler(addr, node) {
+			return
+		}
+	}
+}
+
+// close 等待进行中的slot刷新结束后关闭所有节点
+func (cc *clusterClient) close() (err error) {
+	cc.mu.Lock()
+	cc.closed = true
+	cc.mu.Unlock()
+
+	cc.wg.Wait()
+	cc.rangeNode(func(addr string, node *myPool) bool {
+		if er := node.Close(); er != nil {
+			err = er
+		}
+		return false
+	})
+	return
+}
+
+//endregion
+
+//region route
+
+// route 在slot所在节点执行handler，跟随MOVED与ASK重定向
+func (cc *clusterClient) route(ctx context.Context, slot int, handler func(node *myPool, asking bool) (interface{}, error)) (reply interface{}, err error) {
+	var (
+		node   = cc.nodeBySlot(slot)
+		asking bool
+	)
+
+	for redirect := 0; redirect <= cc.option.MaxRedirects; redirect++ {
+		if node == nil {
+			return nil, ErrNoClusterNode
+		}
+
+		reply, err = handler(node, asking)
+		addr, ask, ok := parseRedirect(err)
+		if !ok {
+			return reply, err
+		}
+
+		if !ask {
+			cc.reloadAsync()
+		}
+
+		if node, err = cc.nodeByAddr(addr); err != nil {
+			return nil, err
+		}
+		asking = ask
+	}
+
+	return nil, ErrTooManyRedirects
+}
+
+func (cc *clusterClient) doSlot(ctx context.Context, slot int, cmd string, args ...interface{}) (reply interface{}, err error) {
+	return cc.route(ctx, slot, func(node *myPool, asking bool) (interface{}, error) {
+		if !asking {
+			return node.DoCtx(ctx, cmd, args...)
+		}
+
+		return node.withConn(ctx, func(conn redigo.Conn) (interface{}, error) {
+			if _, er := conn.Do("ASKING"); er != nil {
+				return nil, er
+			}
+			return conn.Do(cmd, args...)
+		})
+	})
+}
+
+func (cc *clusterClient) do(ctx context.Context, cmd string, args ...interface{}) (reply interface{}, err error) {
+	switch strings.ToUpper(cmd) {
+	case "MGET":
+		return cc.mget(ctx, args)
+	case "DEL", "UNLINK", "EXISTS", "TOUCH":
+		return cc.sumKeys(ctx, cmd, args)
+	case "MSET":
+		return cc.mset(ctx, args)
+	}
+
+	return cc.doSlot(ctx, commandSlot(cmd, args), cmd, args...)
+}
+
+// groupBySlot 按slot对参数分组，step为每个key占用的参数个数
+func groupBySlot(args []interface{}, step int) (slots []int, indexes map[int][]int) {
+	indexes = make(map[int][]int)
+	for index := 0; index+step <= len(args); index += step {
+		slot := Slot(keyString(args[index]))
+		if _, ok := indexes[slot]; !ok {
+			slots = append(slots, slot)
+		}
+		indexes[slot] = append(indexes[slot], index)
+	}
+	return
+}
+
+func (cc *clusterClient) mget(ctx context.Context, keys []interface{}) (reply interface{}, err error) {
+	slots, indexes := groupBySlot(keys, 1)
+	if len(slots) < 2 {
+		return cc.doSlot(ctx, commandSlot("MGET", keys), "MGET", keys...)
+	}
+
+	values := make([]interface{}, len(keys))
+	for _, slot := range slots {
+		args := make([]interface{}, 0, len(indexes[slot]))
+		for _, index := range indexes[slot] {
+			args = append(args, keys[index])
+		}
+
+		res, er := redigo.Values(cc.doSlot(ctx, slot, "MGET", args...))
+		if er != nil {
+			return nil, er
+		}
+
+		for i, index := range indexes[slot] {
+			if i < len(res) {
+				values[index] = res[i]
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func (cc *clusterClient) sumKeys(ctx context.Context, cmd string, keys []interface{}) (reply interface{}, err error) {
+	slots, indexes := groupBySlot(keys, 1)
+	if len(slots) < 2 {
+		return cc.doSlot(ctx, commandSlot(cmd, keys), cmd, keys...)
+	}
+
+	var total int64
+	for _, slot := range slots {
+		args := make([]interface{}, 0, len(indexes[slot]))
+		for _, index := range indexes[slot] {
+			args = append(args, keys[index])
+		}
+
+		num, er := redigo.Int64(cc.doSlot(ctx, slot, cmd, args...))
+		if er != nil {
+			return nil, er
+		}
+		total += num
+	}
+
+	return total, nil
+}
+
+func (cc *clusterClient) mset(ctx context.Context, keyValues []interface{}) (reply interface{}, err error) {
+	slots, indexes := groupBySlot(keyValues, 2)
+	if len(slots) < 2 {
+		return cc.doSlot(ctx, commandSlot("MSET", keyValues), "MSET", keyValues...)
+	}
+
+	for _, slot := range slots {
+		args := make([]interface{}, 0, 2*len(indexes[slot]))
+		for _, index := range indexes[slot] {
+			args = append(args, keyValues[index], keyValues[index+1])
+		}
+
+		if _, err = cc.doSlot(ctx, slot, "MSET", args...); err != nil {
+			return nil, err
+		}
+	}
+
+	return Ok, nil
+}
+
+func (cc *clusterClient) evalOrSha(ctx context.Context, script *redigo.Script, keysAndArgs ...interface{}) (reply interface{}, err error) {
+	slot := -1
+	if len(keysAndArgs) > 0 {
+		slot = Slot(keyString(keysAndArgs[0]))
+	}
+
+	return cc.route(ctx, slot, func(node *myPool, asking bool) (interface{}, error) {
+		if !asking {
+			return node.EvalOrShaCtx(ctx, script, keysAndArgs...)
+		}
+
+		return node.withConn(ctx, func(conn redigo.Conn) (interface{}, error) {
+			if _, er := conn.Do("ASKING"); er != nil {
+				return nil, er
+			}
+			return script.Do(conn, keysAndArgs...)
+		})
+	})
+}
+
+// exec pipeline按节点拆分后并发执行，事务要求所有命令在同一个slot
+func (cc *clusterClient) exec(ctx context.Context, multi Multi) (values []interface{}, err error) {
+	defer ReleaseMulti(multi)
+
+	cmdList := multi.CmdList()
+	if multi.Kind() == Transaction {
+		slot := -1
+		for _, cmd := range cmdList {
+			s := commandSlot(cmd.cmd, cmd.args)
+			if s == -1 {
+				continue
+			}
+
+			if slot != -1 && s != slot {
+				return nil, ErrCrossSlot
+			}
+			slot = s
+		}
+
+		// 命令入队时返回MOVED则跟随重定向在新节点上重新执行整个事务，
+		// ASKING只对下一条命令生效，slot迁移中无法保证事务的所有命令都被目标节点接受
+		return redigo.Values(cc.route(ctx, slot, func(node *myPool, asking bool) (interface{}, error) {
+			if asking {
+				return nil, ErrSlotMigrating
+			}
+			return node.ExecCtx(ctx, cc.copyMulti(Transaction, cmdList, nil))
+		}))
+	}
+
+	var (
+		groups = make(map[*myPool][]int)
+		order  = make([]*myPool, 0)
+	)
+
+	for index, cmd := range cmdList {
+		node := cc.nodeBySlot(commandSlot(cmd.cmd, cmd.args))
+		if node == nil {
+			return nil, ErrNoClusterNode
+		}
+
+		if _, ok := groups[node]; !ok {
+			order = append(order, node)
+		}
+		groups[node] = append(groups[node], index)
+	}
+
+	values = make([]interface{}, len(cmdList))
+
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+	)
+
+	for _, node := range order {
+		wg.Add(1)
+		go func(node *myPool, indexes []int) {
+			defer wg.Done()
+
+			res, er := node.ExecCtx(ctx, cc.copyMulti(Pipeline, cmdList, indexes))
+			if er != nil {
+				errMu.Lock()
+				err = er
+				errMu.Unlock()
+				return
+			}
+
+			for i, index := range indexes {
+				if i >= len(res) {
+					break
+				}
+
+				// 单个命令被重定向时单独重试
+				if re, ok := res[i].(redigo.Error); ok {
+					if _, _, redirect := parseRedirect(re); redirect {
+						cmd := cmdList[index]
+						if reply, e := cc.do(ctx, cmd.cmd, cmd.args...); e != nil {
+							res[i] = e
+						} else {
+							res[i] = reply
+						}
+					}
+				}
+				values[index] = res[i]
+			}
+		}(node, groups[node])
+	}
+
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (cc *clusterClient) copyMulti(kind uint8, cmdList []Cmd, indexes []int) Multi {
+	var m *multi
+	if kind == Transaction {
+		m = TransMulti().(*multi)
+	} else {
+		m = PipeMulti().(*multi)
+	}
+
+	if indexes == nil {
+		m.cmdList = append(m.cmdList, cmdList...)
+		return m
+	}
+
+	for _, index := range indexes {
+		m.cmdList = append(m.cmdList, cmdList[index])
+	}
+	return m
+}
+
+//endregion
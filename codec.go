@@ -0,0 +1,26 @@
+package gedis
+
+import (
+	jsoniter "github.com/json-iterator/go"
+)
+
+var (
+	// JsonCodec json编码，map按key排序，可用作有序集合成员
+	JsonCodec Codec = jsonCodec{}
+)
+
+// Codec 值的编解码，msgpack、gob与protobuf的实现见gedis/typed
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(data, v)
+}
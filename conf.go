@@ -12,6 +12,7 @@ import (
 	"github.com/grpc-boot/base/core/zaplogger"
 	jsoniter "github.com/json-iterator/go"
 	"go.uber.org/atomic"
+	"go.uber.org/zap/zapcore"
 )
 
 const (
@@ -61,11 +62,13 @@ type Conf struct {
 	sub       SubConn
 	cache     sync.Map
 	syncCount atomic.Int64
+	logger    *poolLogger
 }
 
 func NewConf(option ConfOption) (c *Conf, err error) {
 	c = &Conf{
 		option: option,
+		logger: newPoolLogger(option.Option),
 	}
 
 	err = c.init()
@@ -97,7 +100,7 @@ func (c *Conf) init() (err error) {
 	go func() {
 		er := recover()
 		if er != nil {
-			Error("sync data from redis panic",
+			c.logger.log(zapcore.ErrorLevel, msgConfSyncPanic,
 				zaplogger.Error(er.(error)),
 			)
 		}
@@ -134,7 +137,7 @@ func (c *Conf) sync(size int) error {
 	}
 
 	syncAt := time.Now()
-	Debug("start sync config from redis",
+	c.logger.log(zapcore.DebugLevel, msgConfSyncStart,
 		zaplogger.String("Prefix", c.option.Prefix),
 	)
 
@@ -161,7 +164,7 @@ func (c *Conf) sync(size int) error {
 
 		for key, value := range values {
 			if err = c.set(key, value); err != nil {
-				Error("set key failed",
+				c.logger.log(zapcore.ErrorLevel, msgConfSetFailed,
 					zaplogger.Key(key),
 					zaplogger.Value(base.Bytes2String(value)),
 					zaplogger.Error(err),
@@ -174,7 +177,7 @@ func (c *Conf) sync(size int) error {
 
 	c.syncCount.Inc()
 
-	Debug("sync config from redis done",
+	c.logger.log(zapcore.DebugLevel, msgConfSyncDone,
 		zaplogger.String("Prefix", c.option.Prefix),
 		zaplogger.Duration(time.Since(syncAt)),
 		zaplogger.Int64("Count", c.syncCount.Load()),
@@ -200,13 +203,13 @@ func (c *Conf) watch(ctx context.Context) error {
 			case Msg:
 				c.dealCmd(val)
 			case Subscription:
-				Debug("subscribe channel success",
+				c.logger.log(zapcore.DebugLevel, msgConfSubscribed,
 					zaplogger.String("Channel", channelPattern),
 				)
 			case Pong:
 				continue
 			default:
-				Debug("got msg",
+				c.logger.log(zapcore.DebugLevel, msgConfMsg,
 					zaplogger.Any("ConfMsg", msg),
 				)
 			}
@@ -233,7 +236,7 @@ func (c *Conf) dealCmd(msg Msg) {
 	case eventSet:
 		val, err := c.red.GetBytes(redKey)
 		if err != nil {
-			Error("update data failed",
+			c.logger.log(zapcore.ErrorLevel, msgConfUpdateFailed,
 				zaplogger.Error(err),
 				zaplogger.Key(redKey),
 			)
@@ -15,6 +15,7 @@ func Get(key string) (value interface{}) {
 	return value
 }
 
+// SetPool option.URL不为空时按连接地址配置
 func SetPool(key string, option Option) {
 	Set(key, NewPool(option))
 }
@@ -29,6 +30,16 @@ func SetPoolByJson(key string, jsonStr string) (err error) {
 	return err
 }
 
+func SetPoolByURL(key string, rawURL string) (err error) {
+	p, err := NewPoolWithURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	Set(key, p)
+	return err
+}
+
 func GetPool(key string) Pool {
 	val, _ := Get(key).(Pool)
 	return val
@@ -50,6 +61,22 @@ func GetGroup(key string) Group {
 	return g
 }
 
+func SetCluster(key string, option ClusterOption) (err error) {
+	c, err := NewCluster(option)
+	if err != nil {
+		return err
+	}
+
+	Set(key, c)
+
+	return err
+}
+
+func GetCluster(key string) Cluster {
+	c, _ := Get(key).(Cluster)
+	return c
+}
+
 func Range(handler func(key string, pool Pool, group Group) bool) {
 	_container.Range(func(key, value interface{}) bool {
 		switch val := value.(type) {
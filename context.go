@@ -0,0 +1,286 @@
+package gedis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+// timeoutConn 以指定的读超时执行命令
+type timeoutConn struct {
+	redigo.Conn
+	timeout time.Duration
+}
+
+func (tc timeoutConn) Do(cmd string, args ...interface{}) (reply interface{}, err error) {
+	return redigo.DoWithTimeout(tc.Conn, tc.timeout, cmd, args...)
+}
+
+// connHandle 连接池创建连接时生成，ctx取消时关闭底层连接以中断阻塞中的读写，
+// 同一连接同时只会被一个调用使用，关闭后的连接归还时因出错被连接池丢弃
+type connHandle struct {
+	conn    redigo.Conn
+	once    sync.Once
+	ctxs    chan context.Context
+	release chan struct{}
+	stop    chan struct{}
+}
+
+func newConnHandle(conn redigo.Conn) *connHandle {
+	return &connHandle{
+		conn:    conn,
+		ctxs:    make(chan context.Context),
+		release: make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+}
+
+// loop 每个连接一个，在首次watch时启动，连接关闭时退出
+func (h *connHandle) loop() {
+	for {
+		select {
+		case ctx := <-h.ctxs:
+			select {
+			case <-ctx.Done():
+				_ = h.conn.Close()
+				<-h.release
+			case <-h.release:
+			}
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// watch 开始监听ctx，需与unwatch成对调用
+func (h *connHandle) watch(ctx context.Context) {
+	h.once.Do(func() {
+		go h.loop()
+	})
+	h.ctxs <- ctx
+}
+
+// unwatch 停止监听，返回后底层连接不会再被关闭
+func (h *connHandle) unwatch() {
+	h.release <- struct{}{}
+}
+
+// handleRequest 通过空命令取得连接的connHandle，redigo的空命令只读取未决回复，不会写入任何数据
+type handleRequest struct {
+	handle *connHandle
+}
+
+// handleOf 从连接池借出的连接对应的connHandle，不是连接池创建的连接时返回nil
+func handleOf(conn redigo.Conn) *connHandle {
+	req := &handleRequest{}
+	if _, err := conn.Do("", req); err != nil {
+		return nil
+	}
+	return req.handle
+}
+
+// managedConn 连接池创建的连接
+type managedConn struct {
+	redigo.Conn
+	handle *connHandle
+}
+
+func newManagedConn(conn redigo.Conn) *managedConn {
+	return &managedConn{Conn: conn, handle: newConnHandle(conn)}
+}
+
+func (mc *managedConn) Do(cmd string, args ...interface{}) (reply interface{}, err error) {
+	if cmd == "" && len(args) == 1 {
+		if req, ok := args[0].(*handleRequest); ok {
+			req.handle = mc.handle
+			return nil, nil
+		}
+	}
+	return mc.Conn.Do(cmd, args...)
+}
+
+func (mc *managedConn) Close() error {
+	close(mc.handle.stop)
+	return mc.Conn.Close()
+}
+
+func (mc *managedConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (reply interface{}, err error) {
+	return redigo.DoWithTimeout(mc.Conn, timeout, cmd, args...)
+}
+
+func (mc *managedConn) ReceiveWithTimeout(timeout time.Duration) (reply interface{}, err error) {
+	return redigo.ReceiveWithTimeout(mc.Conn, timeout)
+}
+
+// unmanaged 去掉managedConn的包装
+func unmanaged(conn redigo.Conn) redigo.Conn {
+	if mc, ok := conn.(*managedConn); ok {
+		return mc.Conn
+	}
+	return conn
+}
+
+// WithContext 返回绑定ctx的Pool，通过其执行的命令均受ctx的超时与取消控制
+func (mp *myPool) WithContext(ctx context.Context) Pool {
+	return mp.bind(ctx)
+}
+
+func (mp *myPool) bind(ctx context.Context) *myPool {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	p := *mp
+	p.ctx = ctx
+	return &p
+}
+
+func (mp *myPool) getCtx() context.Context {
+	if mp.ctx == nil {
+		return context.Background()
+	}
+	return mp.ctx
+}
+
+type blockKey struct{}
+
+// withBlock 标记阻塞命令的阻塞时长，执行时读超时在连接读超时的基础上增加该时长
+func withBlock(ctx context.Context, block time.Duration) context.Context {
+	if block <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, blockKey{}, block)
+}
+
+// withBlockForever 标记一直阻塞的命令，执行时不设置读超时
+func withBlockForever(ctx context.Context) context.Context {
+	return context.WithValue(ctx, blockKey{}, time.Duration(-1))
+}
+
+// isBlocking 阻塞命令从blockPool获取连接，不计入熔断的慢调用与慢日志
+func isBlocking(ctx context.Context) bool {
+	_, ok := ctx.Value(blockKey{}).(time.Duration)
+	return ok
+}
+
+// listBlock 阻塞列表命令的ctx与超时参数，timeout单位ms，0表示一直阻塞，redis6以下只支持整数秒
+func (mp *myPool) listBlock(timeout int) (context.Context, interface{}) {
+	if timeout <= 0 {
+		return withBlockForever(mp.getCtx()), 0
+	}
+
+	ctx := withBlock(mp.getCtx(), time.Millisecond*time.Duration(timeout))
+	if timeout%1000 == 0 {
+		return ctx, timeout / 1000
+	}
+	return ctx, strconv.FormatFloat(float64(timeout)/1000, 'f', -1, 64)
+}
+
+func blockArgs(keys []string, timeout interface{}) []interface{} {
+	args := make([]interface{}, 0, len(keys)+1)
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	return append(args, timeout)
+}
+
+// timeout 根据ctx的deadline与阻塞时长计算本次调用的读超时，ok为false时使用连接默认的读超时，timeout为0表示不超时
+func (mp *myPool) timeout(ctx context.Context) (timeout time.Duration, ok bool) {
+	readTimeout := mp.readTimeout
+	if block, blocked := ctx.Value(blockKey{}).(time.Duration); blocked {
+		if block < 0 || readTimeout == 0 {
+			readTimeout = 0
+		} else {
+			readTimeout += block
+		}
+		timeout, ok = readTimeout, true
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if !hasDeadline {
+		return
+	}
+
+	if remain := time.Until(deadline); readTimeout == 0 || remain < readTimeout {
+		timeout, ok = remain, true
+		if timeout <= 0 {
+			// 保证已过期的ctx也不会被当作无超时处理
+			timeout = time.Nanosecond
+		}
+	}
+	return
+}
+
+// ctxErr handler出错时ctx的错误，按deadline设置的读超时可能先于ctx的计时器触发
+func ctxErr(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// withConn 经熔断器放行后执行handler，熔断时返回ErrBreakerOpen
+func (mp *myPool) withConn(ctx context.Context, handler func(conn redigo.Conn) (interface{}, error)) (reply interface{}, err error) {
+	if !mp.breaker.allow() {
+		return nil, ErrBreakerOpen
+	}
+
+	start := time.Now()
+	reply, err = mp.execConn(ctx, handler)
+
+	// 阻塞命令的耗时由阻塞时长决定，不计入慢调用
+	duration := time.Since(start)
+	if isBlocking(ctx) {
+		duration = 0
+	}
+	mp.breaker.report(err, duration)
+	return
+}
+
+// execConn 从连接池获取连接并在ctx控制下执行handler，ctx的deadline通过读超时生效，
+// ctx取消时关闭底层连接中断handler并返回ctx.Err()，连接在handler执行结束后归还连接池
+func (mp *myPool) execConn(ctx context.Context, handler func(conn redigo.Conn) (interface{}, error)) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pool := mp.pool
+	if mp.blockPool != nil && isBlocking(ctx) {
+		pool = mp.blockPool
+	}
+
+	start := time.Now()
+	conn, err := pool.GetContext(ctx)
+	mp.counter.connGet.Inc()
+	mp.counter.connWait.Add(int64(time.Since(start)))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if ctx.Done() != nil {
+		if h := handleOf(conn); h != nil {
+			h.watch(ctx)
+			defer h.unwatch()
+		}
+	}
+
+	if timeout, ok := mp.timeout(ctx); ok {
+		conn = timeoutConn{Conn: conn, timeout: timeout}
+	}
+
+	reply, err := handler(conn)
+	if err != nil {
+		if er := ctxErr(ctx); er != nil {
+			return nil, er
+		}
+	}
+	return reply, err
+}
@@ -0,0 +1,66 @@
+package gedis
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// Counter Pool运行计数，均为自实例化以来的累计值
+type Counter struct {
+	//缓存有效直接返回
+	CacheHit uint64
+	//redis中无缓存，同步执行handler
+	CacheMiss uint64
+	//缓存过期，获得锁后执行handler刷新
+	CacheRefresh uint64
+	//LevelCache本地缓存命中
+	LocalCacheHit uint64
+	//限流通过与拒绝次数
+	LimitPass   uint64
+	LimitReject uint64
+	//从连接池获取连接的次数与等待总时长
+	ConnGet  uint64
+	ConnWait time.Duration
+}
+
+type counter struct {
+	cacheHit      atomic.Uint64
+	cacheMiss     atomic.Uint64
+	cacheRefresh  atomic.Uint64
+	localCacheHit atomic.Uint64
+	limitPass     atomic.Uint64
+	limitReject   atomic.Uint64
+	connGet       atomic.Uint64
+	connWait      atomic.Int64
+}
+
+func (c *counter) limit(ok bool, err error) {
+	if err != nil {
+		return
+	}
+
+	if ok {
+		c.limitPass.Inc()
+		return
+	}
+	c.limitReject.Inc()
+}
+
+func (c *counter) load() Counter {
+	return Counter{
+		CacheHit:      c.cacheHit.Load(),
+		CacheMiss:     c.cacheMiss.Load(),
+		CacheRefresh:  c.cacheRefresh.Load(),
+		LocalCacheHit: c.localCacheHit.Load(),
+		LimitPass:     c.limitPass.Load(),
+		LimitReject:   c.limitReject.Load(),
+		ConnGet:       c.connGet.Load(),
+		ConnWait:      time.Duration(c.connWait.Load()),
+	}
+}
+
+// Counter 获取运行计数
+func (mp *myPool) Counter() Counter {
+	return mp.counter.load()
+}
@@ -0,0 +1,247 @@
+package gedis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/atomic"
+)
+
+const (
+	defaultDelayBatchSize    = 100
+	defaultDelayPollInterval = 1000
+	delayPopInterval         = time.Millisecond * 100
+)
+
+var (
+	// delayPollScript 将到期任务移入就绪列表，KEYS: 有序集合、任务内容hash、就绪列表，ARGV: 当前时间(ms)、批量大小
+	delayPollScript = redigo.NewScript(3, `local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+            for _, id in ipairs(ids) do
+                redis.call('ZREM', KEYS[1], id)
+                local payload = redis.call('HGET', KEYS[2], id)
+                if payload then
+                    redis.call('LPUSH', KEYS[3], payload)
+                    redis.call('HDEL', KEYS[2], id)
+                end
+            end
+            return #ids`)
+)
+
+// DelayOption 延时队列配置，集群模式下Name需使用{hash tag}保证相关key在同一slot
+type DelayOption struct {
+	Name string `yaml:"name" json:"name"`
+	//就绪列表，默认为Name:ready，设置为Queue的Name时到期任务可由Queue可靠消费
+	ReadyList string `yaml:"readyList" json:"readyList"`
+	//单次移入就绪列表的最大任务数，默认100
+	BatchSize int `yaml:"batchSize" json:"batchSize"`
+	//轮询间隔，单位ms，默认1000
+	PollInterval int `yaml:"pollInterval" json:"pollInterval"`
+	//为nil时使用包级别的Debug与Error
+	Logger Logger `yaml:"-" json:"-"`
+}
+
+// DelayQueue 基于有序集合的延时队列，任务到期后由轮询原子地移入就绪列表，多实例轮询不会重复投递
+type DelayQueue interface {
+	// Schedule 添加任务，at为到期时间
+	Schedule(payload interface{}, at time.Time) (id string, err error)
+	// Cancel 取消未到期的任务
+	Cancel(id string) (ok bool, err error)
+	// Pop 从就绪列表取出任务，timeout单位ms，0表示一直阻塞，超时返回空字符串
+	Pop(timeout int) (payload string, err error)
+	// Run 阻塞轮询到期任务，ctx取消后返回
+	Run(ctx context.Context) error
+}
+
+type delayQueue struct {
+	option  DelayOption
+	logger  Logger
+	payload string
+	pool    Pool
+	group   Group
+	next    atomic.Uint64
+}
+
+// NewDelayQueue 实例化DelayQueue
+func NewDelayQueue(pool Pool, option DelayOption) DelayQueue {
+	return newDelayQueue(pool, nil, option)
+}
+
+// NewGroupDelayQueue 实例化按任务id分片到Group各节点的DelayQueue
+func NewGroupDelayQueue(group Group, option DelayOption) DelayQueue {
+	return newDelayQueue(nil, group, option)
+}
+
+func newDelayQueue(pool Pool, group Group, option DelayOption) *delayQueue {
+	if option.ReadyList == "" {
+		option.ReadyList = option.Name + ":ready"
+	}
+
+	if option.BatchSize < 1 {
+		option.BatchSize = defaultDelayBatchSize
+	}
+
+	if option.PollInterval < 1 {
+		option.PollInterval = defaultDelayPollInterval
+	}
+
+	dq := &delayQueue{
+		option:  option,
+		logger:  option.Logger,
+		payload: option.Name + ":payload",
+		pool:    pool,
+		group:   group,
+	}
+
+	if dq.logger == nil {
+		dq.logger = defaultLogger{}
+	}
+	return dq
+}
+
+// shard 任务id所在的Pool，只按哈希环位置选择，熔断时不转移到其他节点，保证Schedule与Cancel落在同一分片
+func (dq *delayQueue) shard(id string) (Pool, error) {
+	if dq.group == nil {
+		return dq.pool, nil
+	}
+
+	if g, ok := dq.group.(*group); ok {
+		return g.home(id)
+	}
+	return dq.group.Get(id)
+}
+
+// shards 全部分片，同一redis的虚拟节点只出现一次
+func (dq *delayQueue) shards() []Pool {
+	if dq.group == nil {
+		return []Pool{dq.pool}
+	}
+
+	if g, ok := dq.group.(*group); ok {
+		return g.servers()
+	}
+
+	var (
+		pools []Pool
+		seen  = make(map[uint32]bool)
+	)
+
+	dq.group.Range(func(index int, p Pool, hitCount uint64) (handled bool) {
+		if !seen[p.HashCode()] {
+			seen[p.HashCode()] = true
+			pools = append(pools, p)
+		}
+		return false
+	})
+	return pools
+}
+
+func (dq *delayQueue) Schedule(payload interface{}, at time.Time) (id string, err error) {
+	buf := make([]byte, 16)
+	if _, err = rand.Read(buf); err != nil {
+		return
+	}
+	id = hex.EncodeToString(buf)
+
+	pool, err := dq.shard(id)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = pool.Exec(TransMulti().
+		HSet(dq.payload, id, payload).
+		ZAdd(dq.option.Name, at.UnixNano()/int64(time.Millisecond), id),
+	)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (dq *delayQueue) Cancel(id string) (ok bool, err error) {
+	pool, err := dq.shard(id)
+	if err != nil {
+		return false, err
+	}
+
+	values, err := pool.Exec(TransMulti().ZRem(dq.option.Name, id).HDel(dq.payload, id))
+	if err != nil {
+		return false, err
+	}
+
+	removeNum, err := redigo.Int(values[0], nil)
+	return removeNum > 0, err
+}
+
+func (dq *delayQueue) Pop(timeout int) (payload string, err error) {
+	if dq.group == nil {
+		_, payload, err = dq.pool.BRPop(timeout, dq.option.ReadyList)
+		return
+	}
+
+	// 分片模式下轮流从各节点的就绪列表取出任务
+	var (
+		pools    = dq.shards()
+		deadline = time.Now().Add(time.Millisecond * time.Duration(timeout))
+	)
+
+	for {
+		for range pools {
+			pool := pools[dq.next.Inc()%uint64(len(pools))]
+			if payload, err = pool.RPop(dq.option.ReadyList); err != nil || payload != "" {
+				return
+			}
+		}
+
+		wait := delayPopInterval
+		if timeout > 0 {
+			remain := time.Until(deadline)
+			if remain <= 0 {
+				return "", nil
+			}
+
+			if remain < wait {
+				wait = remain
+			}
+		}
+		time.Sleep(wait)
+	}
+}
+
+func (dq *delayQueue) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Millisecond * time.Duration(dq.option.PollInterval))
+	defer ticker.Stop()
+
+	for {
+		for _, pool := range dq.shards() {
+			dq.poll(ctx, pool)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll 移入到期任务，单次达到批量大小时继续移入
+func (dq *delayQueue) poll(ctx context.Context, pool Pool) {
+	for ctx.Err() == nil {
+		num, err := pool.EvalOrSha4Int64(delayPollScript,
+			dq.option.Name, dq.payload, dq.option.ReadyList,
+			time.Now().UnixNano()/int64(time.Millisecond), dq.option.BatchSize,
+		)
+		if err != nil {
+			dq.logger.Error(msgDelayPollFailed, zaplogger.Key(dq.option.Name), zaplogger.Error(err))
+			return
+		}
+
+		if num < int64(dq.option.BatchSize) {
+			return
+		}
+	}
+}
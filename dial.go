@@ -0,0 +1,141 @@
+package gedis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+var (
+	ErrTLSCaFile = NewError(`tls: no certificate found in ca file`)
+)
+
+type dialer func(addr string) (redigo.Conn, error)
+
+// newDialer 根据配置构造连接函数，readTimeout为连接默认读超时，TLS配置只加载一次
+func newDialer(option Option, readTimeout time.Duration) dialer {
+	var dialOptions = []redigo.DialOption{
+		redigo.DialConnectTimeout(time.Millisecond * time.Duration(option.ConnectTimeout)),
+		redigo.DialReadTimeout(readTimeout),
+		redigo.DialWriteTimeout(time.Millisecond * time.Duration(option.WriteTimeout)),
+	}
+
+	if option.DialFunc != nil {
+		dialOptions = append(dialOptions, redigo.DialNetDial(option.DialFunc))
+	}
+
+	tlsConfig, tlsErr := option.tlsConfig()
+	if tlsConfig != nil {
+		dialOptions = append(dialOptions,
+			redigo.DialUseTLS(true),
+			redigo.DialTLSConfig(tlsConfig),
+			redigo.DialTLSSkipVerify(option.TLSSkipVerify),
+		)
+	}
+
+	return func(addr string) (redigo.Conn, error) {
+		if tlsErr != nil {
+			return nil, tlsErr
+		}
+
+		conn, err := redigo.Dial(option.network(), addr, dialOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = option.prepare(conn); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+}
+
+// prepare 新连接认证、选择db、设置连接名称并执行初始化命令，配置Username时使用redis6的ACL认证
+func (o *Option) prepare(conn redigo.Conn) (err error) {
+	if len(o.Username) > 0 {
+		_, err = conn.Do("AUTH", o.Username, o.Auth)
+	} else if len(o.Auth) > 0 {
+		_, err = conn.Do("AUTH", o.Auth)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if o.Db > 0 {
+		if _, err = conn.Do("SELECT", o.Db); err != nil {
+			return err
+		}
+	}
+
+	if name := o.clientName(); name != "" {
+		if _, err = conn.Do("CLIENT", "SETNAME", name); err != nil {
+			return err
+		}
+	}
+
+	for _, line := range o.InitCmds {
+		cmd, args := o.initCmd(line)
+		if cmd == "" {
+			continue
+		}
+
+		if _, err = conn.Do(cmd, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// initCmd 按空白拆分初始化命令
+func (o *Option) initCmd(line string) (cmd string, args []interface{}) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	args = make([]interface{}, len(fields)-1)
+	for index, field := range fields[1:] {
+		args[index] = field
+	}
+	return fields[0], args
+}
+
+// tlsConfig 未开启TLS时返回nil
+func (o *Option) tlsConfig() (*tls.Config, error) {
+	if !o.TLS {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		ServerName:         o.TLSServerName,
+		InsecureSkipVerify: o.TLSSkipVerify,
+	}
+
+	if len(o.TLSCertFile) > 0 || len(o.TLSKeyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(o.TLSCaFile) > 0 {
+		ca, err := ioutil.ReadFile(o.TLSCaFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, ErrTLSCaFile
+		}
+	}
+
+	return config, nil
+}
@@ -1,8 +1,10 @@
 package gedis
 
 var (
-	ErrKeyFormat = NewError(`key format error`)
-	ErrKeyList   = NewError(`key list is empty`)
+	ErrKeyFormat      = NewError(`key format error`)
+	ErrKeyList        = NewError(`key list is empty`)
+	ErrNoMaster       = NewError(`sentinel: no master found`)
+	ErrMasterSwitched = NewError(`sentinel: master switched`)
 )
 
 type GedisError struct {
@@ -55,7 +55,7 @@ func (mp *myPool) GeoHash(key string, members ...interface{}) (hashList []string
 
 // GeoDel redis命令
 func (mp *myPool) GeoDel(key string, members ...interface{}) (removeNum int, err error) {
-	return mp.ZRem(key, members)
+	return mp.ZRem(key, members...)
 }
 
 // GeoDist redis命令
@@ -77,6 +77,14 @@ func (mp *myPool) GeoPos(key string, members ...interface{}) (positionList []Pos
 	return Positions(mp.Do("GEOPOS", args...))
 }
 
+// geoReadOnly 配置从库时使用只读的_RO命令，GEORADIUS可带STORE参数写入，不能发往从库
+func (mp *myPool) geoReadOnly(cmd string) string {
+	if mp.replicas == nil {
+		return cmd
+	}
+	return cmd + "_RO"
+}
+
 // GeoRadius redis命令
 func (mp *myPool) GeoRadius(key string, longitude, latitude float64, radius interface{}, unit string, count int, sort string) (locationList []Location, err error) {
 	if sort == "" {
@@ -84,10 +92,10 @@ func (mp *myPool) GeoRadius(key string, longitude, latitude float64, radius inte
 	}
 
 	if count == 0 {
-		return Locations(mp.Do("GEORADIUS", key, longitude, latitude, radius, unit, "WITHCOORD", "WITHHASH", "WITHDIST", sort))
+		return Locations(mp.Do(mp.geoReadOnly("GEORADIUS"), key, longitude, latitude, radius, unit, "WITHCOORD", "WITHHASH", "WITHDIST", sort))
 	}
 
-	return Locations(mp.Do("GEORADIUS", key, longitude, latitude, radius, unit, "WITHCOORD", "WITHHASH", "WITHDIST", "COUNT", count, sort))
+	return Locations(mp.Do(mp.geoReadOnly("GEORADIUS"), key, longitude, latitude, radius, unit, "WITHCOORD", "WITHHASH", "WITHDIST", "COUNT", count, sort))
 }
 
 // GeoRadiusByMember redis命令
@@ -97,10 +105,10 @@ func (mp *myPool) GeoRadiusByMember(key string, member interface{}, radius inter
 	}
 
 	if count == 0 {
-		return Locations(mp.Do("GEORADIUSBYMEMBER", key, member, radius, unit, "WITHCOORD", "WITHHASH", "WITHDIST", sort))
+		return Locations(mp.Do(mp.geoReadOnly("GEORADIUSBYMEMBER"), key, member, radius, unit, "WITHCOORD", "WITHHASH", "WITHDIST", sort))
 	}
 
-	return Locations(mp.Do("GEORADIUSBYMEMBER", key, member, radius, unit, "WITHCOORD", "WITHHASH", "WITHDIST", "COUNT", count, sort))
+	return Locations(mp.Do(mp.geoReadOnly("GEORADIUSBYMEMBER"), key, member, radius, unit, "WITHCOORD", "WITHHASH", "WITHDIST", "COUNT", count, sort))
 
 }
 
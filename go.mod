@@ -1,12 +1,18 @@
 module github.com/grpc-boot/gedis
 
-go 1.16
+go 1.18
 
 require (
 	github.com/garyburd/redigo v1.6.3
 	github.com/grpc-boot/base v1.2.24
 	github.com/json-iterator/go v1.1.12
+	github.com/prometheus/client_golang v1.12.2
 	github.com/shopspring/decimal v1.3.1
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	go.opentelemetry.io/otel v1.7.0
+	go.opentelemetry.io/otel/sdk v1.7.0
+	go.opentelemetry.io/otel/trace v1.7.0
 	go.uber.org/atomic v1.9.0
 	go.uber.org/zap v1.20.0
+	google.golang.org/protobuf v1.27.1
 )
@@ -2,6 +2,7 @@ package gedis
 
 import (
 	"errors"
+	"sort"
 
 	"github.com/grpc-boot/base"
 )
@@ -18,6 +19,12 @@ type Group interface {
 
 type group struct {
 	ring base.HashRing
+	//熔断时需要跳过的节点
+	skip map[base.CanHash]bool
+	//按HashCode排序的节点，即哈希环的顺时针顺序
+	sorted []base.CanHash
+	//节点所属redis在options中的索引，同一redis的虚拟节点相同
+	owner map[base.CanHash]int
 }
 
 // NewGroup 实例化Group
@@ -28,35 +35,119 @@ func NewGroup(options ...GroupOption) (g Group, err error) {
 
 	var poolSize = 0
 	for _, option := range options {
+		if _, err = option.Option.resolveURL(); err != nil {
+			return nil, err
+		}
+
 		poolSize += 1
 		poolSize += option.VirtualCount
 	}
 
-	poolList := make([]base.CanHash, 0, poolSize)
-	for _, option := range options {
+	var (
+		poolList = make([]base.CanHash, 0, poolSize)
+		skip     = make(map[base.CanHash]bool)
+		owner    = make(map[base.CanHash]int, poolSize)
+	)
+
+	for index, option := range options {
+		var br *breaker
 		for s := 0; s <= option.VirtualCount; s++ {
 			option.Option.Index = s
-			poolList = append(poolList, NewPool(option.Option))
+			p := NewPool(option.Option).(*myPool)
+
+			// 同一redis的虚拟节点共享熔断器
+			if s == 0 {
+				br = p.breaker
+			} else {
+				p.breaker = br
+			}
+
+			if option.SkipOpenCircuit && br != nil {
+				skip[p] = true
+			}
+			poolList = append(poolList, p)
+			owner[p] = index
 		}
 	}
 
+	sorted := append([]base.CanHash(nil), poolList...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].HashCode() < sorted[j].HashCode()
+	})
+
 	g = &group{
-		ring: base.NewHashRing(poolList...),
+		ring:   base.NewHashRing(poolList...),
+		skip:   skip,
+		sorted: sorted,
+		owner:  owner,
 	}
 	return g, nil
 }
 
 // Get 根据key获取Pool
 func (g *group) Get(key interface{}) (pool Pool, err error) {
-	var r base.CanHash
-	r, err = g.ring.Get(key)
-	if err != nil {
+	if pool, err = g.home(key); err != nil {
 		return nil, err
 	}
 
+	if g.skip[pool] && pool.BreakerState() == BreakerOpen {
+		return g.next(pool), nil
+	}
+
+	return pool, nil
+}
+
+// home key在哈希环上所在的Pool，不考虑熔断状态
+func (g *group) home(key interface{}) (pool Pool, err error) {
+	r, err := g.ring.Get(key)
+	if err != nil {
+		return nil, err
+	}
 	return r.(Pool), nil
 }
 
+// next 从熔断节点在哈希环上的位置顺时针查找第一个未熔断的节点，跳过同一redis的虚拟节点，
+// 使同一节点上的key尽量转移到同一个后继节点，全部熔断时返回原节点
+func (g *group) next(server base.CanHash) Pool {
+	start := sort.Search(len(g.sorted), func(i int) bool {
+		return g.sorted[i].HashCode() >= server.HashCode()
+	})
+
+	// HashCode相同时定位到节点本身
+	for start < len(g.sorted) && g.sorted[start] != server {
+		start++
+	}
+
+	for offset := 1; offset < len(g.sorted); offset++ {
+		s := g.sorted[(start+offset)%len(g.sorted)]
+		if g.owner[s] == g.owner[server] {
+			continue
+		}
+
+		if p := s.(Pool); p.BreakerState() != BreakerOpen {
+			return p
+		}
+	}
+
+	return server.(Pool)
+}
+
+// servers 每个redis取一个Pool，同一redis的虚拟节点只保留HashCode最小的一个
+func (g *group) servers() []Pool {
+	var (
+		pools = make([]Pool, 0, len(g.sorted))
+		seen  = make(map[int]bool, len(g.sorted))
+	)
+
+	for _, s := range g.sorted {
+		if !seen[g.owner[s]] {
+			seen[g.owner[s]] = true
+			pools = append(pools, s.(Pool))
+		}
+	}
+	return pools
+}
+
 // Index 根据索引获取Pool
 func (g *group) Index(index int) (pool Pool, err error) {
 	r, err := g.ring.Index(index)
@@ -0,0 +1,406 @@
+package gedis
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+var (
+	ErrNotStruct     = NewError(`struct: value must be a struct or pointer to struct`)
+	ErrStructPointer = NewError(`struct: dst must be a non-nil pointer to struct`)
+	ErrStructEmpty   = NewError(`struct: no fields to set`)
+)
+
+var (
+	// StructCodec 结构体中嵌套类型(struct、map、slice等)的编码
+	StructCodec = JsonCodec
+
+	structFieldsCache sync.Map
+	timeType          = reflect.TypeOf(time.Time{})
+	bytesType         = reflect.TypeOf([]byte(nil))
+)
+
+// structField 结构体字段与hash field的对应关系，由tag `redis:"name,omitempty"`指定，`redis:"-"`忽略
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields 类型的字段列表，匿名结构体及结构体指针字段未指定tag时展开
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+
+	fields := appendStructFields(nil, t, nil, map[reflect.Type]bool{})
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// appendStructFields visiting为正在展开的类型，避免匿名指针循环嵌套时无限递归
+func appendStructFields(fields []structField, t reflect.Type, index []int, visiting map[reflect.Type]bool) []structField {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			f         = t.Field(i)
+			tag       = f.Tag.Get("redis")
+			name      = f.Name
+			omitEmpty bool
+		)
+
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if tag == "" && f.Anonymous {
+			if f.Type.Kind() == reflect.Struct && f.Type != timeType {
+				fields = appendStructFields(fields, f.Type, fieldIndex, visiting)
+				continue
+			}
+
+			// 未导出的结构体指针无法在读取时分配内存，与encoding/json一致忽略
+			if ft := f.Type; ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct && ft.Elem() != timeType {
+				if f.PkgPath == "" && !visiting[ft.Elem()] {
+					fields = appendStructFields(fields, ft.Elem(), fieldIndex, visiting)
+				}
+				continue
+			}
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if tag != "" {
+			opts := strings.Split(tag, ",")
+			if opts[0] != "" {
+				name = opts[0]
+			}
+
+			for _, opt := range opts[1:] {
+				if opt == "omitempty" {
+					omitEmpty = true
+				}
+			}
+		}
+
+		fields = append(fields, structField{name: name, index: fieldIndex, omitEmpty: omitEmpty})
+	}
+	return fields
+}
+
+// structValue src对应的结构体
+func structValue(src interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(src)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, ErrNotStruct
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return v, ErrNotStruct
+	}
+	return v, nil
+}
+
+// structPointer dst指向的结构体
+func structPointer(dst interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return v, ErrStructPointer
+	}
+	return v.Elem(), nil
+}
+
+// structFieldNames 结构体的全部hash field
+func structFieldNames(src interface{}) ([]string, error) {
+	v, err := structValue(src)
+	if err != nil {
+		// 允许传入结构体的nil指针，仅用于获取字段
+		t := reflect.TypeOf(src)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t == nil || t.Kind() != reflect.Struct {
+			return nil, err
+		}
+		v = reflect.New(t).Elem()
+	}
+
+	fields := structFields(v.Type())
+	names := make([]string, len(fields))
+	for index, f := range fields {
+		names[index] = f.name
+	}
+	return names, nil
+}
+
+// hashStructArgs HMSET参数，omitempty的零值与nil指针被忽略
+func hashStructArgs(key string, src interface{}) ([]interface{}, error) {
+	v, err := structValue(src)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := structFields(v.Type())
+	args := make([]interface{}, 0, 2*len(fields)+1)
+	args = append(args, key)
+	for _, f := range fields {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok || (f.omitEmpty && fv.IsZero()) {
+			continue
+		}
+
+		value, ok, err := encodeField(fv)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			args = append(args, f.name, value)
+		}
+	}
+	return args, nil
+}
+
+// fieldByIndex 按index取字段，alloc为true时为nil的匿名结构体指针分配内存
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return v, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func encodeField(v reflect.Value) (value interface{}, ok bool, err error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), true, nil
+	case v.Type() == bytesType:
+		return v.Bytes(), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		if v.Bool() {
+			return "1", true, nil
+		}
+		return "0", true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true, nil
+	}
+
+	data, err := StructCodec.Marshal(v.Interface())
+	return data, err == nil, err
+}
+
+func decodeField(v reflect.Value, data []byte) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == timeType:
+		t, err := time.Parse(time.RFC3339Nano, string(data))
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case v.Type() == bytesType:
+		v.SetBytes(append([]byte(nil), data...))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(data))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(string(data))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(string(data), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(string(data), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(string(data), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return StructCodec.Unmarshal(data, v.Addr().Interface())
+	}
+	return nil
+}
+
+// scanStruct 将field与值写入dst，未知的field被忽略
+func scanStruct(dst reflect.Value, values map[string][]byte) error {
+	for _, f := range structFields(dst.Type()) {
+		data, ok := values[f.name]
+		if !ok {
+			continue
+		}
+
+		fv, _ := fieldByIndex(dst, f.index, true)
+		if err := decodeField(fv, data); err != nil {
+			return fmt.Errorf("struct: decode field %s failed: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+//region 1.14 Hash Struct
+
+// HGetAllStruct 读取hash写入dst，hash不存在时exists为false
+func (mp *myPool) HGetAllStruct(key string, dst interface{}) (exists bool, err error) {
+	if _, err = structPointer(dst); err != nil {
+		return
+	}
+
+	reply, err := mp.Do("HGETALL", key)
+	if err != nil {
+		return
+	}
+	return ScanHashStruct(reply, dst)
+}
+
+// HMSetStruct 将src的字段写入hash，全部字段都被忽略时返回ErrStructEmpty
+func (mp *myPool) HMSetStruct(key string, src interface{}) (ok bool, err error) {
+	args, err := hashStructArgs(key, src)
+	if err != nil {
+		return false, err
+	}
+
+	if len(args) == 1 {
+		return false, ErrStructEmpty
+	}
+
+	res, err := redigo.String(mp.Do("HMSET", args...))
+	return res == Ok, err
+}
+
+// HMGetStruct 读取dst的字段写入dst，fields为空时读取全部字段，全部字段都不存在时exists为false
+func (mp *myPool) HMGetStruct(key string, dst interface{}, fields ...string) (exists bool, err error) {
+	if _, err = structPointer(dst); err != nil {
+		return
+	}
+
+	if len(fields) == 0 {
+		if fields, err = structFieldNames(dst); err != nil {
+			return
+		}
+	}
+
+	args := make([]interface{}, 0, len(fields)+1)
+	args = append(args, key)
+	for _, field := range fields {
+		args = append(args, field)
+	}
+
+	reply, err := mp.Do("HMGET", args...)
+	if err != nil {
+		return
+	}
+	return ScanHashFields(reply, dst, fields...)
+}
+
+//endregion
+
+// HMSetStruct 编码失败时Exec返回该错误，全部字段都被忽略时返回ErrStructEmpty，避免结果与命令错位
+func (m *multi) HMSetStruct(key string, src interface{}) Multi {
+	args, err := hashStructArgs(key, src)
+	if err != nil {
+		m.setErr(err)
+		return m
+	}
+
+	if len(args) == 1 {
+		m.setErr(ErrStructEmpty)
+		return m
+	}
+
+	m.cmdList = append(m.cmdList, Cmd{cmd: "HMSET", args: args})
+	return m
+}
+
+// HMGetStruct HMGET命令，dst仅用于确定字段，结果使用ScanHashFields转换
+func (m *multi) HMGetStruct(key string, dst interface{}, fields ...string) Multi {
+	if len(fields) == 0 {
+		var err error
+		if fields, err = structFieldNames(dst); err != nil {
+			m.setErr(err)
+			return m
+		}
+	}
+	return m.HMGet(key, fields...)
+}
+
+// hashValues 将HMGET结果按fields转换为map，nil回复被忽略
+func hashValues(fields []string, reply interface{}, err error) (map[string][]byte, error) {
+	list, err := redigo.ByteSlices(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]byte, len(list))
+	for index, data := range list {
+		if data != nil && index < len(fields) {
+			values[fields[index]] = data
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,134 @@
+package gedis
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+type HookKind uint8
+
+const (
+	HookCmd HookKind = iota + 1
+	HookPipeline
+	HookTransaction
+	HookScript
+)
+
+func (hk HookKind) String() string {
+	switch hk {
+	case HookCmd:
+		return "cmd"
+	case HookPipeline:
+		return "pipeline"
+	case HookTransaction:
+		return "transaction"
+	case HookScript:
+		return "script"
+	}
+	return "unknown"
+}
+
+// HookEvent 一次redis调用的信息，Before时Duration、Reply、Err为空
+type HookEvent struct {
+	Kind HookKind
+	//节点标识
+	Id string
+	//单个命令与脚本时有效，脚本的Cmd为EVALSHA，Args为keysAndArgs
+	Cmd  string
+	Args []interface{}
+	//pipeline与事务时有效
+	CmdList []Cmd
+	//脚本时有效
+	Script   *redigo.Script
+	Start    time.Time
+	Duration time.Duration
+	Reply    interface{}
+	Err      error
+}
+
+// Hook 命令钩子，可用于监控、链路追踪、审计与故障注入
+type Hook interface {
+	// Before 执行前调用，返回的ctx传递给后续钩子与命令执行；返回error时命令不再执行并返回该error
+	Before(ctx context.Context, event *HookEvent) (context.Context, error)
+	// After 执行后调用，Before返回error的钩子同样会调用；ctx为该钩子Before返回的ctx，
+	// 返回nil时为传入Before的ctx，便于取回Before中创建的span等数据
+	After(ctx context.Context, event *HookEvent)
+}
+
+// HookFunc 只关心执行结果的钩子
+type HookFunc func(ctx context.Context, event *HookEvent)
+
+func (hf HookFunc) Before(ctx context.Context, event *HookEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+func (hf HookFunc) After(ctx context.Context, event *HookEvent) {
+	hf(ctx, event)
+}
+
+// hooks 写时复制的钩子列表，同一Pool的所有副本共享
+type hooks struct {
+	mu   sync.Mutex
+	list atomic.Value
+}
+
+func (h *hooks) add(hs ...Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old := h.load()
+	list := make([]Hook, 0, len(old)+len(hs))
+	list = append(list, old...)
+	list = append(list, hs...)
+	h.list.Store(list)
+}
+
+func (h *hooks) empty() bool {
+	return len(h.load()) == 0
+}
+
+func (h *hooks) load() []Hook {
+	list, _ := h.list.Load().([]Hook)
+	return list
+}
+
+// process 依次调用Before，执行handler后倒序调用After，每个钩子的After收到的是其Before返回的ctx
+func (h *hooks) process(ctx context.Context, event *HookEvent, handler func(ctx context.Context) (interface{}, error)) (reply interface{}, err error) {
+	list := h.load()
+	ctxList := make([]context.Context, 0, len(list))
+	for _, hook := range list {
+		var hookCtx context.Context
+		hookCtx, err = hook.Before(ctx, event)
+		if hookCtx != nil {
+			ctx = hookCtx
+		}
+
+		ctxList = append(ctxList, ctx)
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		reply, err = handler(ctx)
+	}
+
+	event.Duration = time.Since(event.Start)
+	event.Reply = reply
+	event.Err = err
+
+	for index := len(ctxList) - 1; index > -1; index-- {
+		list[index].After(ctxList[index], event)
+	}
+
+	return reply, err
+}
+
+// AddHook 添加命令钩子，对当前Pool及其WithContext返回的副本均生效
+func (mp *myPool) AddHook(hs ...Hook) {
+	mp.hooks.add(hs...)
+}
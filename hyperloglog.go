@@ -0,0 +1,63 @@
+package gedis
+
+import (
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+func keysArgs(first string, keys []string) []interface{} {
+	args := make([]interface{}, 0, len(keys)+1)
+	if first != "" {
+		args = append(args, first)
+	}
+
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	return args
+}
+
+//region 1.13 HyperLogLog
+
+// PFAdd redis命令，基数估计值变化时返回1
+func (mp *myPool) PFAdd(key string, elements ...interface{}) (changed int, err error) {
+	args := make([]interface{}, 0, len(elements)+1)
+	args = append(args, key)
+	args = append(args, elements...)
+	return redigo.Int(mp.Do("PFADD", args...))
+}
+
+// PFCount redis命令，多个key时返回并集的基数估计值
+func (mp *myPool) PFCount(keys ...string) (count int64, err error) {
+	return redigo.Int64(mp.Do("PFCOUNT", keysArgs("", keys)...))
+}
+
+// PFMerge redis命令
+func (mp *myPool) PFMerge(destKey string, sourceKeys ...string) (ok bool, err error) {
+	var res string
+	res, err = String(mp.Do("PFMERGE", keysArgs(destKey, sourceKeys)...))
+	return res == Ok, err
+}
+
+//endregion
+
+// PFAdd redis命令
+func (m *multi) PFAdd(key string, elements ...interface{}) Multi {
+	args := make([]interface{}, 0, len(elements)+1)
+	args = append(args, key)
+	args = append(args, elements...)
+
+	m.cmdList = append(m.cmdList, Cmd{cmd: "PFADD", args: args})
+	return m
+}
+
+// PFCount redis命令
+func (m *multi) PFCount(keys ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "PFCOUNT", args: keysArgs("", keys)})
+	return m
+}
+
+// PFMerge redis命令
+func (m *multi) PFMerge(destKey string, sourceKeys ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "PFMERGE", args: keysArgs(destKey, sourceKeys)})
+	return m
+}
@@ -0,0 +1,380 @@
+package gedis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+// 分数提交方式
+const (
+	LeaderboardBest   = `best`
+	LeaderboardLatest = `latest`
+	LeaderboardSum    = `sum`
+)
+
+// 排行榜周期
+const (
+	LeaderboardAll    = ``
+	LeaderboardDaily  = `daily`
+	LeaderboardWeekly = `weekly`
+)
+
+const (
+	// leaderboardAllSpan 总榜同分排序的时间跨度，单位s，约34年
+	leaderboardAllSpan = 1 << 30
+)
+
+var (
+	// leaderboardEpoch 总榜同分排序的起始时间
+	leaderboardEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// leaderboardSumScript 累加分数并重新编码提交时间，KEYS: 有序集合，ARGV: 增量、时间跨度、时间编码、成员、过期时间戳(s)
+	leaderboardSumScript = redigo.NewScript(1, `local span = tonumber(ARGV[2])
+            local total = tonumber(ARGV[1])
+            local old = redis.call('ZSCORE', KEYS[1], ARGV[4])
+            if old then
+                total = total + math.floor(tonumber(old) / span)
+            end
+            redis.call('ZADD', KEYS[1], total * span + tonumber(ARGV[3]), ARGV[4])
+            if tonumber(ARGV[5]) > 0 then
+                redis.call('EXPIREAT', KEYS[1], ARGV[5])
+            end
+            return string.format('%.17g', total)`)
+
+	// leaderboardRangeScript 按排名读取成员、分数与元数据，KEYS: 有序集合、元数据hash，ARGV: 起始、结束、成员
+	// 指定成员时ARGV[1]、ARGV[2]分别为成员之前与之后的数量，返回[起始排名, 成员, 分数, 元数据, ...]
+	leaderboardRangeScript = redigo.NewScript(2, `local start, stop = tonumber(ARGV[1]), tonumber(ARGV[2])
+            if ARGV[3] ~= '' then
+                local rank = redis.call('ZREVRANK', KEYS[1], ARGV[3])
+                if not rank then
+                    return {}
+                end
+                start, stop = math.max(rank - start, 0), rank + stop
+            end
+            local values = redis.call('ZREVRANGE', KEYS[1], start, stop, 'WITHSCORES')
+            if #values == 0 then
+                return {}
+            end
+            local members = {}
+            for i = 1, #values, 2 do
+                members[#members + 1] = values[i]
+            end
+            local metas = redis.call('HMGET', KEYS[2], unpack(members))
+            local result = {start}
+            for i = 1, #members do
+                result[#result + 1] = members[i]
+                result[#result + 1] = values[i * 2]
+                result[#result + 1] = metas[i]
+            end
+            return result`)
+)
+
+// LeaderboardOption 排行榜配置，集群模式下Name需使用{hash tag}保证榜单与元数据在同一slot
+type LeaderboardOption struct {
+	Name string `yaml:"name" json:"name"`
+	//分数提交方式，best保留最高分(需要redis6.2以上)，latest保留最新分数，sum累加，默认best
+	Mode string `yaml:"mode" json:"mode"`
+	//周期，daily按天、weekly按周(周一开始)分榜，默认为总榜
+	Period string `yaml:"period" json:"period"`
+	//同分时先提交者排名靠前，开启后分数需为整数，总榜分数绝对值不超过8388608，日榜与周榜不超过10^10
+	TieBreak bool `yaml:"tieBreak" json:"tieBreak"`
+	//周期榜单结束后保留的时长，单位s，默认为一个周期
+	RetentionSecond int `yaml:"retentionSecond" json:"retentionSecond"`
+	//周期划分使用的时区，默认为time.Local
+	Location *time.Location `yaml:"-" json:"-"`
+}
+
+// LeaderboardEntry 排行榜条目
+type LeaderboardEntry struct {
+	//排名，从1开始
+	Rank   int
+	Member string
+	Score  float64
+	Meta   string
+}
+
+// Leaderboard 基于有序集合的排行榜，分数从高到低排名
+type Leaderboard interface {
+	// Key 当前周期榜单的key
+	Key() string
+	// At 返回t所在周期的榜单，用于读取历史榜单
+	At(t time.Time) Leaderboard
+	// Submit 提交分数，返回成员在榜单中的分数
+	Submit(member string, score float64) (newScore float64, err error)
+	// SetMeta 设置成员元数据，元数据不区分周期
+	SetMeta(member string, meta interface{}) error
+	// DelMeta 删除成员元数据
+	DelMeta(members ...string) error
+	// Rank 成员的排名、分数与元数据，不在榜单中时exists为false
+	Rank(member string) (entry LeaderboardEntry, exists bool, err error)
+	// Top 前n名
+	Top(n int) (entries []LeaderboardEntry, err error)
+	// Around 成员及其前后各n名，成员不在榜单中时返回nil
+	Around(member string, n int) (entries []LeaderboardEntry, err error)
+	// Remove 从当前周期榜单删除成员
+	Remove(members ...string) (removeNum int, err error)
+	// Count 当前周期榜单的成员数
+	Count() (count int, err error)
+}
+
+type leaderboard struct {
+	pool   Pool
+	option LeaderboardOption
+	meta   string
+	at     time.Time
+}
+
+// NewLeaderboard 实例化Leaderboard
+func NewLeaderboard(pool Pool, option LeaderboardOption) Leaderboard {
+	if option.Mode == "" {
+		option.Mode = LeaderboardBest
+	}
+
+	if option.Location == nil {
+		option.Location = time.Local
+	}
+
+	return &leaderboard{
+		pool:   pool,
+		option: option,
+		meta:   option.Name + ":meta",
+	}
+}
+
+// bucket t所在周期的key、起止时间，总榜的结束时间为零值
+func (lb *leaderboard) bucket(t time.Time) (key string, start, end time.Time) {
+	t = t.In(lb.option.Location)
+	start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, lb.option.Location)
+
+	switch lb.option.Period {
+	case LeaderboardDaily:
+		return lb.option.Name + ":" + start.Format("20060102"), start, start.AddDate(0, 0, 1)
+	case LeaderboardWeekly:
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%s:%dW%02d", lb.option.Name, year, week), start, start.AddDate(0, 0, 7)
+	default:
+		return lb.option.Name, leaderboardEpoch, time.Time{}
+	}
+}
+
+func (lb *leaderboard) current() (key string, start, end time.Time) {
+	if lb.at.IsZero() {
+		return lb.bucket(time.Now())
+	}
+	return lb.bucket(lb.at)
+}
+
+// span 同分排序的时间跨度，单位s
+func (lb *leaderboard) span(start, end time.Time) float64 {
+	if end.IsZero() {
+		return leaderboardAllSpan
+	}
+	return end.Sub(start).Seconds()
+}
+
+// tie 提交时间的编码，越早提交值越大
+func (lb *leaderboard) tie(start time.Time, span float64) float64 {
+	elapsed := math.Floor(time.Since(start).Seconds())
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	if elapsed > span-1 {
+		elapsed = span - 1
+	}
+	return span - 1 - elapsed
+}
+
+// expireAt 周期榜单的过期时间戳，总榜返回0
+func (lb *leaderboard) expireAt(start, end time.Time) int64 {
+	if end.IsZero() {
+		return 0
+	}
+
+	retention := time.Duration(lb.option.RetentionSecond) * time.Second
+	if retention <= 0 {
+		retention = end.Sub(start)
+	}
+	return end.Add(retention).Unix()
+}
+
+// decode 还原编码了提交时间的分数
+func (lb *leaderboard) decode(score, span float64) float64 {
+	if !lb.option.TieBreak {
+		return score
+	}
+	return math.Floor(score / span)
+}
+
+func (lb *leaderboard) Key() string {
+	key, _, _ := lb.current()
+	return key
+}
+
+func (lb *leaderboard) At(t time.Time) Leaderboard {
+	l := *lb
+	l.at = t
+	return &l
+}
+
+func (lb *leaderboard) Submit(member string, score float64) (newScore float64, err error) {
+	var (
+		key, start, end = lb.current()
+		span            = lb.span(start, end)
+		expireAt        = lb.expireAt(start, end)
+	)
+
+	if lb.option.Mode == LeaderboardSum && lb.option.TieBreak {
+		reply, err := lb.pool.EvalOrSha(leaderboardSumScript, key, math.Floor(score), span, lb.tie(start, span), member, expireAt)
+		return redigo.Float64(reply, err)
+	}
+
+	var (
+		m = TransMulti()
+		// 结果中新分数的位置
+		index int
+	)
+
+	switch lb.option.Mode {
+	case LeaderboardSum:
+		m.ZIncrBy(key, score, member)
+	default:
+		if lb.option.TieBreak {
+			score = math.Floor(score)*span + lb.tie(start, span)
+		}
+
+		var flags []ZAddFlag
+		if lb.option.Mode == LeaderboardBest {
+			flags = append(flags, ZAddGt)
+		}
+		m.ZAddMembers(key, []ZMember{{Member: member, Score: score}}, flags...).ZScore(key, member)
+		index = 1
+	}
+
+	if expireAt > 0 {
+		m.ExpireAt(key, expireAt)
+	}
+
+	values, err := lb.pool.Exec(m)
+	if err != nil {
+		return 0, err
+	}
+
+	newScore, err = redigo.Float64(values[index], nil)
+	if err != nil {
+		return 0, err
+	}
+	return lb.decode(newScore, span), nil
+}
+
+func (lb *leaderboard) SetMeta(member string, meta interface{}) error {
+	_, err := lb.pool.HSet(lb.meta, member, meta)
+	return err
+}
+
+func (lb *leaderboard) DelMeta(members ...string) error {
+	_, err := lb.pool.HDel(lb.meta, members...)
+	return err
+}
+
+func (lb *leaderboard) Rank(member string) (entry LeaderboardEntry, exists bool, err error) {
+	key, start, end := lb.current()
+	values, err := lb.pool.Exec(PipeMulti().ZRevRank(key, member).ZScore(key, member).HGet(lb.meta, member))
+	if err != nil || values[0] == nil {
+		return
+	}
+
+	rank, err := redigo.Int(values[0], nil)
+	if err != nil {
+		return
+	}
+
+	score, err := redigo.Float64(values[1], nil)
+	if err != nil {
+		return
+	}
+
+	meta, err := String(values[2], nil)
+	if err != nil {
+		return
+	}
+
+	return LeaderboardEntry{
+		Rank:   rank + 1,
+		Member: member,
+		Score:  lb.decode(score, lb.span(start, end)),
+		Meta:   meta,
+	}, true, nil
+}
+
+func (lb *leaderboard) Top(n int) (entries []LeaderboardEntry, err error) {
+	if n < 1 {
+		return nil, nil
+	}
+	return lb.entries(0, n-1, "")
+}
+
+func (lb *leaderboard) Around(member string, n int) (entries []LeaderboardEntry, err error) {
+	if n < 0 {
+		n = 0
+	}
+	return lb.entries(n, n, member)
+}
+
+func (lb *leaderboard) entries(start, stop int, member string) ([]LeaderboardEntry, error) {
+	key, bucketStart, bucketEnd := lb.current()
+	values, err := redigo.Values(lb.pool.EvalOrSha(leaderboardRangeScript, key, lb.meta, start, stop, member))
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+
+	if len(values)%3 != 1 {
+		return nil, errors.New("gedis: unexpected leaderboard range result")
+	}
+
+	rank, err := redigo.Int(values[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		span    = lb.span(bucketStart, bucketEnd)
+		entries = make([]LeaderboardEntry, 0, len(values)/3)
+	)
+
+	for i := 1; i < len(values); i += 3 {
+		entry := LeaderboardEntry{Rank: rank + len(entries) + 1}
+		if entry.Member, err = String(values[i], nil); err != nil {
+			return nil, err
+		}
+
+		if entry.Score, err = redigo.Float64(values[i+1], nil); err != nil {
+			return nil, err
+		}
+		entry.Score = lb.decode(entry.Score, span)
+
+		if entry.Meta, err = String(values[i+2], nil); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (lb *leaderboard) Remove(members ...string) (removeNum int, err error) {
+	args := make([]interface{}, len(members))
+	for index, member := range members {
+		args[index] = member
+	}
+	return lb.pool.ZRem(lb.Key(), args...)
+}
+
+func (lb *leaderboard) Count() (count int, err error) {
+	return lb.pool.ZCard(lb.Key())
+}
@@ -1,6 +1,7 @@
 package gedis
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -22,6 +23,7 @@ func (mp *myPool) LevelCache(localCache *sync.Map, key string, current, timeoutS
 	if ok {
 		// 本地缓存命中或者获得锁失败
 		if ent.hit(timeoutSecond, current) || !ent.lock(current, lockTimeoutSecond) {
+			mp.counter.localCacheHit.Inc()
 			ent.access(current)
 			return ent.getValue(), nil
 		}
@@ -40,6 +42,7 @@ func (mp *myPool) LevelCache(localCache *sync.Map, key string, current, timeoutS
 	item := Item{}
 	//redis中没有数据
 	if redisValue == nil {
+		mp.counter.cacheMiss.Inc()
 		err = mp.updateCache(key, &item, current, handler)
 		if err == nil {
 			// 更新本地缓存
@@ -70,6 +73,7 @@ func (mp *myPool) LevelCache(localCache *sync.Map, key string, current, timeoutS
 
 	//-------------------未获取到redis数据-----------------------
 	if item.UpdatedAt == 0 || !ok {
+		mp.counter.cacheMiss.Inc()
 		err = mp.updateCache(key, &item, current, handler)
 		if err == nil {
 			// 更新本地缓存
@@ -87,6 +91,7 @@ func (mp *myPool) LevelCache(localCache *sync.Map, key string, current, timeoutS
 
 	//-------------------缓存有效-----------------------
 	if item.Hit(timeoutSecond, current) {
+		mp.counter.cacheHit.Inc()
 		// 更新本地缓存
 		if ent == nil {
 			localCache.Store(key, newEntry(current, item.Value))
@@ -101,10 +106,12 @@ func (mp *myPool) LevelCache(localCache *sync.Map, key string, current, timeoutS
 	token, _ := mp.Acquire(key, lockTimeoutSecond)
 	//未获得锁
 	if token == 0 {
+		mp.counter.cacheHit.Inc()
 		return item.Value, nil
 	}
 
 	// 获得锁
+	mp.counter.cacheRefresh.Inc()
 	err = mp.updateCache(key, &item, current, handler)
 	if err == nil {
 		// 更新本地缓存
@@ -118,3 +125,8 @@ func (mp *myPool) LevelCache(localCache *sync.Map, key string, current, timeoutS
 
 	return item.Value, err
 }
+
+// LevelCacheCtx 二级缓存，redis操作受ctx的超时与取消控制
+func (mp *myPool) LevelCacheCtx(ctx context.Context, localCache *sync.Map, key string, current, timeoutSecond int64, handler Handler) (value []byte, err error) {
+	return mp.bind(ctx).LevelCache(localCache, key, current, timeoutSecond, handler)
+}
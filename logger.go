@@ -1,8 +1,45 @@
 package gedis
 
 import (
+	"time"
+
 	"github.com/grpc-boot/base"
+	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	msgCmdFailed      = "do redis cmd failed"
+	msgPipelineFailed = "do redis pipeline failed"
+	msgMultiFailed    = "do redis multi failed"
+	msgSlowCmd        = "slow redis cmd"
+	msgSlowMulti      = "slow redis multi"
+	msgPingFailed     = "PING redis failed"
+	msgCacheFailed    = "cache exec handler failed"
+	msgBreakerChanged = "redis circuit breaker state changed"
+
+	msgWorkerReadFailed   = "stream worker read failed"
+	msgWorkerHandleFailed = "stream worker handle message failed"
+	msgWorkerAckFailed    = "stream worker ack failed"
+	msgWorkerDeadLetter   = "stream worker move message to dead letter"
+	msgQueueFailed        = "queue heartbeat or reap failed"
+	msgQueueReaped        = "queue requeue jobs of dead worker"
+	msgDelayPollFailed    = "delay queue poll failed"
+
+	msgSentinelQueryFailed     = "query master from sentinel failed"
+	msgSentinelSubscribeFailed = "subscribe sentinel failed"
+	msgMasterSwitched          = "redis master switched"
+	msgClusterLoadFailed       = "load cluster slots failed"
+	msgSubReconnectFailed      = "subscribe connection reconnect failed"
+	msgConfSyncPanic           = "sync data from redis panic"
+	msgConfSyncStart           = "start sync config from redis"
+	msgConfSyncDone            = "sync config from redis done"
+	msgConfSetFailed           = "set key failed"
+	msgConfUpdateFailed        = "update data failed"
+	msgConfSubscribed          = "subscribe channel success"
+	msgConfMsg                 = "got msg"
 )
 
 var (
@@ -11,3 +48,186 @@ var (
 )
 
 type Log func(msg string, fields ...zap.Field)
+
+// Logger 日志接口，可通过Option.Logger为每个Pool设置，*zap.Logger可直接使用
+type Logger interface {
+	Debug(msg string, fields ...zap.Field)
+	Info(msg string, fields ...zap.Field)
+	Warn(msg string, fields ...zap.Field)
+	Error(msg string, fields ...zap.Field)
+}
+
+// LogOption 日志配置
+type LogOption struct {
+	//最低日志级别: debug、info、warn、error，默认debug
+	Level string `yaml:"level" json:"level"`
+	//每秒同一消息先记录SampleFirst条，之后每SampleThereafter条记录一条，SampleFirst为0时不采样
+	SampleFirst      int `yaml:"sampleFirst" json:"sampleFirst"`
+	SampleThereafter int `yaml:"sampleThereafter" json:"sampleThereafter"`
+	//不记录命令参数，只记录参数个数
+	RedactArgs bool `yaml:"redactArgs" json:"redactArgs"`
+	//慢命令阈值，单位ms，0表示不记录慢命令
+	SlowThreshold int `yaml:"slowThreshold" json:"slowThreshold"`
+}
+
+// defaultLogger 使用包级别的Debug与Error，未设置Option.Logger时使用
+type defaultLogger struct{}
+
+func (defaultLogger) Debug(msg string, fields ...zap.Field) {
+	Debug(msg, fields...)
+}
+
+func (defaultLogger) Info(msg string, fields ...zap.Field) {
+	Debug(msg, fields...)
+}
+
+func (defaultLogger) Warn(msg string, fields ...zap.Field) {
+	Error(msg, fields...)
+}
+
+func (defaultLogger) Error(msg string, fields ...zap.Field) {
+	Error(msg, fields...)
+}
+
+type sampleCounter struct {
+	second atomic.Int64
+	count  atomic.Uint64
+}
+
+// poolLogger 按级别过滤、采样并脱敏的Pool日志
+type poolLogger struct {
+	logger  Logger
+	level   zapcore.Level
+	option  LogOption
+	slow    time.Duration
+	samples map[string]*sampleCounter
+}
+
+func newPoolLogger(option Option) *poolLogger {
+	pl := &poolLogger{
+		logger:  option.Logger,
+		level:   zapcore.DebugLevel,
+		option:  option.Log,
+		slow:    time.Millisecond * time.Duration(option.Log.SlowThreshold),
+		samples: make(map[string]*sampleCounter),
+	}
+
+	if pl.logger == nil {
+		pl.logger = defaultLogger{}
+	}
+
+	if option.Log.Level != "" {
+		if err := pl.level.UnmarshalText([]byte(option.Log.Level)); err != nil {
+			pl.level = zapcore.DebugLevel
+		}
+	}
+
+	// 采样计数器预先创建，运行时只读
+	for _, msg := range []string{
+		msgCmdFailed, msgPipelineFailed, msgMultiFailed, msgSlowCmd, msgSlowMulti, msgPingFailed, msgCacheFailed,
+		msgSentinelQueryFailed, msgSentinelSubscribeFailed, msgClusterLoadFailed, msgSubReconnectFailed,
+		msgConfSetFailed, msgConfUpdateFailed, msgConfMsg,
+	} {
+		pl.samples[msg] = &sampleCounter{}
+	}
+	return pl
+}
+
+func (pl *poolLogger) log(level zapcore.Level, msg string, fields ...zap.Field) {
+	if level < pl.level || !pl.sample(msg) {
+		return
+	}
+
+	switch level {
+	case zapcore.DebugLevel:
+		pl.logger.Debug(msg, fields...)
+	case zapcore.InfoLevel:
+		pl.logger.Info(msg, fields...)
+	case zapcore.WarnLevel:
+		pl.logger.Warn(msg, fields...)
+	default:
+		pl.logger.Error(msg, fields...)
+	}
+}
+
+// sample 同一消息每秒先记录SampleFirst条，之后每SampleThereafter条记录一条
+func (pl *poolLogger) sample(msg string) bool {
+	if pl.option.SampleFirst < 1 {
+		return true
+	}
+
+	sc, ok := pl.samples[msg]
+	if !ok {
+		return true
+	}
+
+	now := time.Now().Unix()
+	if old := sc.second.Load(); old != now && sc.second.CAS(old, now) {
+		sc.count.Store(0)
+	}
+
+	count := sc.count.Inc()
+	if count <= uint64(pl.option.SampleFirst) {
+		return true
+	}
+
+	if pl.option.SampleThereafter < 1 {
+		return false
+	}
+	return (count-uint64(pl.option.SampleFirst))%uint64(pl.option.SampleThereafter) == 0
+}
+
+func (pl *poolLogger) args(args []interface{}) zap.Field {
+	if pl.option.RedactArgs {
+		return zap.Int("ArgCount", len(args))
+	}
+	return zaplogger.Args(args...)
+}
+
+// cmd 记录失败与慢命令
+func (pl *poolLogger) cmd(id string, cmd string, args []interface{}, err error, duration time.Duration) {
+	if err != nil {
+		pl.log(zapcore.ErrorLevel, msgCmdFailed,
+			zaplogger.Addr(id),
+			zaplogger.Error(err),
+			zaplogger.Cmd(cmd),
+			pl.args(args),
+			zaplogger.Duration(duration),
+		)
+		return
+	}
+
+	if pl.slow > 0 && duration >= pl.slow {
+		pl.log(zapcore.WarnLevel, msgSlowCmd,
+			zaplogger.Addr(id),
+			zaplogger.Cmd(cmd),
+			pl.args(args),
+			zaplogger.Duration(duration),
+		)
+	}
+}
+
+// multi 记录失败与慢pipeline、事务
+func (pl *poolLogger) multi(id string, kind uint8, size int, err error, duration time.Duration) {
+	if err != nil {
+		msg := msgMultiFailed
+		if kind == Pipeline {
+			msg = msgPipelineFailed
+		}
+
+		pl.log(zapcore.ErrorLevel, msg,
+			zaplogger.Addr(id),
+			zaplogger.Error(err),
+			zaplogger.Duration(duration),
+		)
+		return
+	}
+
+	if pl.slow > 0 && duration >= pl.slow {
+		pl.log(zapcore.WarnLevel, msgSlowMulti,
+			zaplogger.Addr(id),
+			zap.Int("CmdCount", size),
+			zaplogger.Duration(duration),
+		)
+	}
+}
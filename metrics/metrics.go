@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/gedis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	DefaultBuckets = []float64{.0005, .001, .002, .005, .01, .025, .05, .1, .25, .5, 1}
+)
+
+// Metrics 采集容器中所有Pool、Group与Cluster的指标，实现了prometheus.Collector
+type Metrics struct {
+	latency *prometheus.HistogramVec
+	errors  *prometheus.CounterVec
+
+	connections *prometheus.Desc
+	connGets    *prometheus.Desc
+	connWait    *prometheus.Desc
+	groupHits   *prometheus.Desc
+	cache       *prometheus.Desc
+	limit       *prometheus.Desc
+
+	instrumented sync.Map
+}
+
+// New 实例化Metrics，buckets为空时使用DefaultBuckets，单位为秒
+func New(namespace string, buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	return &Metrics{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "redis_cmd_duration_seconds",
+			Help:      "Redis command latency in seconds.",
+			Buckets:   buckets,
+		}, []string{"name", "node", "cmd"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "redis_cmd_errors_total",
+			Help:      "Redis command errors by type.",
+		}, []string{"name", "node", "cmd", "type"}),
+		connections: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "redis_pool_connections"),
+			"Redis pool connections by state.", []string{"name", "node", "state"}, nil),
+		connGets: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "redis_pool_conn_gets_total"),
+			"Connections taken from the redis pool.", []string{"name", "node"}, nil),
+		connWait: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "redis_pool_conn_wait_seconds_total"),
+			"Time spent waiting for redis pool connections.", []string{"name", "node"}, nil),
+		groupHits: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "redis_group_hits_total"),
+			"Keys routed to each redis group node.", []string{"name", "node", "index"}, nil),
+		cache: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "redis_cache_total"),
+			"CacheGet and LevelCache results.", []string{"name", "node", "result"}, nil),
+		limit: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "redis_limit_total"),
+			"Rate limiter results.", []string{"name", "node", "result"}, nil),
+	}
+}
+
+// Register 为容器中的Pool添加钩子并注册到reg，reg为nil时使用prometheus.DefaultRegisterer
+func (m *Metrics) Register(reg prometheus.Registerer) error {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	m.Instrument()
+	return reg.Register(m)
+}
+
+// Instrument 为容器中尚未添加钩子的Pool及Group节点添加钩子，采集时会自动调用
+func (m *Metrics) Instrument() {
+	gedis.Range(func(key string, pool gedis.Pool, group gedis.Group) bool {
+		if pool != nil {
+			m.instrument(key, pool)
+			return true
+		}
+
+		group.Range(func(index int, p gedis.Pool, hitCount uint64) (handled bool) {
+			m.instrument(key, p)
+			return false
+		})
+		return true
+	})
+}
+
+func (m *Metrics) instrument(name string, pool gedis.Pool) {
+	if _, loaded := m.instrumented.LoadOrStore(pool, struct{}{}); loaded {
+		return
+	}
+
+	pool.AddHook(m.Hook(name))
+}
+
+// Hook 记录命令耗时与错误的钩子，用于未加入容器的Pool
+func (m *Metrics) Hook(name string) gedis.Hook {
+	return gedis.HookFunc(func(ctx context.Context, event *gedis.HookEvent) {
+		cmd := cmdLabel(event)
+		m.latency.WithLabelValues(name, event.Id, cmd).Observe(event.Duration.Seconds())
+
+		if event.Err != nil && event.Err != redigo.ErrNil {
+			m.errors.WithLabelValues(name, event.Id, cmd, ErrorType(event.Err)).Inc()
+		}
+	})
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.latency.Describe(ch)
+	m.errors.Describe(ch)
+	ch <- m.connections
+	ch <- m.connGets
+	ch <- m.connWait
+	ch <- m.groupHits
+	ch <- m.cache
+	ch <- m.limit
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.Instrument()
+
+	m.latency.Collect(ch)
+	m.errors.Collect(ch)
+
+	gedis.Range(func(key string, pool gedis.Pool, group gedis.Group) bool {
+		if pool != nil {
+			m.collectPool(ch, key, pool)
+			return true
+		}
+
+		group.Range(func(index int, p gedis.Pool, hitCount uint64) (handled bool) {
+			m.collectPool(ch, key, p)
+			ch <- prometheus.MustNewConstMetric(m.groupHits, prometheus.CounterValue, float64(hitCount), key, p.Id(), strconv.Itoa(index))
+			return false
+		})
+		return true
+	})
+}
+
+func (m *Metrics) collectPool(ch chan<- prometheus.Metric, name string, pool gedis.Pool) {
+	node := pool.Id()
+	stats := pool.Stats()
+	counter := pool.Counter()
+
+	ch <- prometheus.MustNewConstMetric(m.connections, prometheus.GaugeValue, float64(stats.ActiveCount-stats.IdleCount), name, node, "inuse")
+	ch <- prometheus.MustNewConstMetric(m.connections, prometheus.GaugeValue, float64(stats.IdleCount), name, node, "idle")
+	ch <- prometheus.MustNewConstMetric(m.connGets, prometheus.CounterValue, float64(counter.ConnGet), name, node)
+	ch <- prometheus.MustNewConstMetric(m.connWait, prometheus.CounterValue, counter.ConnWait.Seconds(), name, node)
+
+	ch <- prometheus.MustNewConstMetric(m.cache, prometheus.CounterValue, float64(counter.CacheHit), name, node, "hit")
+	ch <- prometheus.MustNewConstMetric(m.cache, prometheus.CounterValue, float64(counter.CacheMiss), name, node, "miss")
+	ch <- prometheus.MustNewConstMetric(m.cache, prometheus.CounterValue, float64(counter.CacheRefresh), name, node, "refresh")
+	ch <- prometheus.MustNewConstMetric(m.cache, prometheus.CounterValue, float64(counter.LocalCacheHit), name, node, "local_hit")
+
+	ch <- prometheus.MustNewConstMetric(m.limit, prometheus.CounterValue, float64(counter.LimitPass), name, node, "pass")
+	ch <- prometheus.MustNewConstMetric(m.limit, prometheus.CounterValue, float64(counter.LimitReject), name, node, "reject")
+}
+
+func cmdLabel(event *gedis.HookEvent) string {
+	switch event.Kind {
+	case gedis.HookCmd:
+		return strings.ToUpper(event.Cmd)
+	case gedis.HookScript:
+		return "EVALSHA"
+	}
+	return event.Kind.String()
+}
+
+// ErrorType 错误分类: timeout、canceled、pool_exhausted、network、redis返回的错误前缀(如WRONGTYPE)或other
+func ErrorType(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+
+	if errors.Is(err, redigo.ErrPoolExhausted) {
+		return "pool_exhausted"
+	}
+
+	var redisErr redigo.Error
+	if errors.As(err, &redisErr) {
+		prefix := string(redisErr)
+		if index := strings.IndexByte(prefix, ' '); index > 0 {
+			prefix = prefix[:index]
+		}
+
+		// 只使用ERR、WRONGTYPE等大写前缀，避免自定义错误导致标签过多
+		if prefix != strings.ToUpper(prefix) || len(prefix) > 16 {
+			return "redis"
+		}
+		return prefix
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+
+	return "other"
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/gedis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	option := gedis.DefaultOption()
+	option.Host = "127.0.0.1"
+	option.Port = 1
+	gedis.SetPool("metrics", option)
+
+	m := New("test")
+	reg := prometheus.NewRegistry()
+	if err := m.Register(reg); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := gedis.GetPool("metrics")
+	if _, err := pool.Get("key"); err == nil {
+		t.Fatal("want dial error")
+	}
+
+	if count := testutil.CollectAndCount(m, "test_redis_cmd_duration_seconds"); count != 1 {
+		t.Fatalf("want 1 latency series, got %d", count)
+	}
+
+	want := `
+# HELP test_redis_cmd_errors_total Redis command errors by type.
+# TYPE test_redis_cmd_errors_total counter
+test_redis_cmd_errors_total{cmd="GET",name="metrics",node="127.0.0.1:1-0",type="network"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "test_redis_cmd_errors_total"); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := testutil.CollectAndCount(m, "test_redis_cache_total"); count < 4 {
+		t.Fatalf("want cache series, got %d", count)
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	cases := map[error]string{
+		context.DeadlineExceeded:                    "timeout",
+		context.Canceled:                            "canceled",
+		redigo.ErrPoolExhausted:                     "pool_exhausted",
+		redigo.Error("WRONGTYPE Operation against"): "WRONGTYPE",
+		redigo.Error("custom script error"):         "redis",
+		errors.New("unknown"):                       "other",
+	}
+
+	for err, want := range cases {
+		if got := ErrorType(err); got != want {
+			t.Fatalf("%v: want %s, got %s", err, want, got)
+		}
+	}
+}
@@ -71,6 +71,9 @@ type Multi interface {
 	SetBit(key string, offset int, bit int8) Multi
 	GetBit(key string, offset int) Multi
 	BitCount(key string, args ...interface{}) Multi
+	BitOp(operation, destKey string, keys ...string) Multi
+	BitPos(key string, bit int8, args ...interface{}) Multi
+	BitField(key string, bf *BitFieldArgs) Multi
 
 	//-----------------Hash--------------------------
 	HSet(key string, field string, value interface{}) Multi
@@ -80,6 +83,8 @@ type Multi interface {
 	HMSetMap(key string, keyValues map[string]interface{}) Multi
 	HMGet(key string, fields ...string) Multi
 	HGetAll(key string) Multi
+	HMSetStruct(key string, src interface{}) Multi
+	HMGetStruct(key string, dst interface{}, fields ...string) Multi
 	HDel(key string, fields ...string) Multi
 	HExists(key string, field string) Multi
 	HIncrBy(key string, field string, increment int) Multi
@@ -100,6 +105,12 @@ type Multi interface {
 	RPush(key string, values ...interface{}) Multi
 	RPushX(key string, value interface{}) Multi
 	RPop(key string) Multi
+	LMove(source, destination, srcSide, dstSide string) Multi
+	LPos(key string, element interface{}, rank, maxLen int) Multi
+	LPosCount(key string, element interface{}, rank, count, maxLen int) Multi
+	LInsert(key, position string, pivot, value interface{}) Multi
+	LRem(key string, count int, value interface{}) Multi
+	RPopLPush(source, destination string) Multi
 
 	//--------------------Set---------------------------
 	SAdd(key string, members ...interface{}) Multi
@@ -118,6 +129,7 @@ type Multi interface {
 	SUnionStore(destinationSetKey string, keys ...string) Multi
 
 	//--------------------ZSet---------------------------
+	//WITHSCORES的结果可用ZMembers按顺序转换，ZSCORE、ZINCRBY的结果可用Float64Ptr转换
 	ZAdd(key string, score, value interface{}, scoreAndValues ...interface{}) Multi
 	ZAddMap(key string, membersMap map[string]interface{}) Multi
 	ZCard(key string) Multi
@@ -136,8 +148,25 @@ type Multi interface {
 	ZScore(key, member string) Multi
 	ZRem(key string, members ...interface{}) Multi
 	ZRemRangeByRank(key string, startIndex, stopIndex int) Multi
-
-	//--------------------ZSet---------------------------
+	ZRemRangeByScore(key string, minScore, maxScore interface{}) Multi
+	ZAddMembers(key string, members []ZMember, flags ...ZAddFlag) Multi
+	ZAddIncr(key string, member string, increment float64, flags ...ZAddFlag) Multi
+	ZPopMin(key string, count int) Multi
+	ZPopMax(key string, count int) Multi
+	ZRangeByLex(key, min, max string, offset, limit int) Multi
+	ZRevRangeByLex(key, max, min string, offset, limit int) Multi
+	ZLexCount(key, min, max string) Multi
+	ZRemRangeByLex(key, min, max string) Multi
+	ZUnionStore(destination string, keys []string, weights []float64, aggregate string) Multi
+	ZInterStore(destination string, keys []string, weights []float64, aggregate string) Multi
+	ZDiff(keys ...string) Multi
+	ZDiffWithScores(keys ...string) Multi
+	ZDiffStore(destination string, keys ...string) Multi
+	ZMScore(key string, members ...string) Multi
+	ZRandMember(key string, count int) Multi
+	ZRandMemberWithScores(key string, count int) Multi
+
+	//----------------------Geo-----------------------------
 	GeoAdd(key string, longitude, latitude float64, member interface{}, args ...interface{}) Multi
 	GeoHash(key string, members ...interface{}) Multi
 	GeoDel(key string, members ...interface{}) Multi
@@ -146,6 +175,33 @@ type Multi interface {
 	GeoRadius(key string, longitude, latitude float64, radius interface{}, unit string, count int, sort string) Multi
 	GeoRadiusByMember(key string, member interface{}, radius interface{}, unit string, count int, sort string) Multi
 
+	//--------------------HyperLogLog---------------------------
+	PFAdd(key string, elements ...interface{}) Multi
+	PFCount(keys ...string) Multi
+	PFMerge(destKey string, sourceKeys ...string) Multi
+
+	//--------------------Stream---------------------------
+	XAdd(key, id string, trim *StreamTrim, fields map[string]interface{}) Multi
+	XRange(key, start, end string, count int) Multi
+	XRevRange(key, end, start string, count int) Multi
+	XRead(count int, streams map[string]string) Multi
+	XGroupCreate(key, group, id string, mkStream bool) Multi
+	XGroupDestroy(key, group string) Multi
+	XGroupSetId(key, group, id string) Multi
+	XGroupDelConsumer(key, group, consumer string) Multi
+	XReadGroup(group, consumer string, count int, noAck bool, streams map[string]string) Multi
+	XAck(key, group string, ids ...string) Multi
+	XPending(key, group string) Multi
+	XPendingExt(key, group string, minIdle int, start, end string, count int, consumer string) Multi
+	XClaim(key, group, consumer string, minIdle int, ids ...string) Multi
+	XAutoClaim(key, group, consumer string, minIdle int, start string, count int) Multi
+	XTrim(key string, trim StreamTrim) Multi
+	XDel(key string, ids ...string) Multi
+	XLen(key string) Multi
+	XInfoStream(key string) Multi
+	XInfoGroups(key string) Multi
+	XInfoConsumers(key, group string) Multi
+
 	Reset()
 	Kind() uint8
 	CmdList() []Cmd
@@ -156,9 +212,20 @@ type Cmd struct {
 	args []interface{}
 }
 
+// Name 命令名称
+func (c Cmd) Name() string {
+	return c.cmd
+}
+
+// Args 命令参数
+func (c Cmd) Args() []interface{} {
+	return c.args
+}
+
 type multi struct {
 	kind    uint8
 	cmdList []Cmd
+	err     error
 }
 
 func (m *multi) Del(keys ...interface{}) Multi {
@@ -576,6 +643,36 @@ func (m *multi) RPop(key string) Multi {
 	return m
 }
 
+func (m *multi) LMove(source, destination, srcSide, dstSide string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "LMOVE", args: []interface{}{source, destination, srcSide, dstSide}})
+	return m
+}
+
+func (m *multi) LPos(key string, element interface{}, rank, maxLen int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "LPOS", args: lPosArgs(key, element, rank, -1, maxLen)})
+	return m
+}
+
+func (m *multi) LPosCount(key string, element interface{}, rank, count, maxLen int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "LPOS", args: lPosArgs(key, element, rank, count, maxLen)})
+	return m
+}
+
+func (m *multi) LInsert(key, position string, pivot, value interface{}) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "LINSERT", args: []interface{}{key, position, pivot, value}})
+	return m
+}
+
+func (m *multi) LRem(key string, count int, value interface{}) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "LREM", args: []interface{}{key, count, value}})
+	return m
+}
+
+func (m *multi) RPopLPush(source, destination string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "RPOPLPUSH", args: []interface{}{source, destination}})
+	return m
+}
+
 func (m *multi) SAdd(key string, members ...interface{}) Multi {
 	var (
 		args = make([]interface{}, len(members)+1)
@@ -834,9 +931,105 @@ func (m *multi) ZRemRangeByScore(key string, minScore, maxScore interface{}) Mul
 	return m
 }
 
+func (m *multi) ZAddMembers(key string, members []ZMember, flags ...ZAddFlag) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZADD", args: zAddArgs(key, flags, false, members)})
+	return m
+}
+
+func (m *multi) ZAddIncr(key string, member string, increment float64, flags ...ZAddFlag) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZADD", args: zAddArgs(key, flags, true, []ZMember{{Member: member, Score: increment}})})
+	return m
+}
+
+func (m *multi) ZPopMin(key string, count int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZPOPMIN", args: zPopArgs(key, count)})
+	return m
+}
+
+func (m *multi) ZPopMax(key string, count int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZPOPMAX", args: zPopArgs(key, count)})
+	return m
+}
+
+func (m *multi) ZRangeByLex(key, min, max string, offset, limit int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZRANGEBYLEX", args: zLexArgs(key, min, max, offset, limit)})
+	return m
+}
+
+func (m *multi) ZRevRangeByLex(key, max, min string, offset, limit int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZREVRANGEBYLEX", args: zLexArgs(key, max, min, offset, limit)})
+	return m
+}
+
+func (m *multi) ZLexCount(key, min, max string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZLEXCOUNT", args: []interface{}{key, min, max}})
+	return m
+}
+
+func (m *multi) ZRemRangeByLex(key, min, max string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZREMRANGEBYLEX", args: []interface{}{key, min, max}})
+	return m
+}
+
+func (m *multi) ZUnionStore(destination string, keys []string, weights []float64, aggregate string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZUNIONSTORE", args: zStoreArgs(destination, keys, weights, aggregate)})
+	return m
+}
+
+func (m *multi) ZInterStore(destination string, keys []string, weights []float64, aggregate string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZINTERSTORE", args: zStoreArgs(destination, keys, weights, aggregate)})
+	return m
+}
+
+func (m *multi) ZDiff(keys ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZDIFF", args: zStoreArgs("", keys, nil, "")})
+	return m
+}
+
+func (m *multi) ZDiffWithScores(keys ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZDIFF", args: append(zStoreArgs("", keys, nil, ""), "WITHSCORES")})
+	return m
+}
+
+func (m *multi) ZDiffStore(destination string, keys ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZDIFFSTORE", args: zStoreArgs(destination, keys, nil, "")})
+	return m
+}
+
+func (m *multi) ZMScore(key string, members ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZMSCORE", args: keysArgs(key, members)})
+	return m
+}
+
+func (m *multi) ZRandMember(key string, count int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZRANDMEMBER", args: []interface{}{key, count}})
+	return m
+}
+
+func (m *multi) ZRandMemberWithScores(key string, count int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "ZRANDMEMBER", args: []interface{}{key, count, "WITHSCORES"}})
+	return m
+}
+
 func (m *multi) Reset() {
 	m.kind = 0
 	m.cmdList = m.cmdList[:0]
+	m.err = nil
+}
+
+// setErr 记录第一个构造命令时的错误，如HMSetStruct编码失败，Exec直接返回该错误
+func (m *multi) setErr(err error) {
+	if m.err == nil {
+		m.err = err
+	}
+}
+
+// multiErr 构造命令时的错误，其它Multi实现返回nil
+func multiErr(m Multi) error {
+	if mu, ok := m.(*multi); ok {
+		return mu.err
+	}
+	return nil
 }
 
 func (m *multi) Kind() uint8 {
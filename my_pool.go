@@ -1,14 +1,17 @@
 package gedis
 
 import (
+	"context"
 	"fmt"
 	"hash/crc32"
 	"strings"
+	"sync"
 	"time"
 
 	redigo "github.com/garyburd/redigo/redis"
 	"github.com/grpc-boot/base"
 	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/zap/zapcore"
 )
 
 const (
@@ -20,6 +23,13 @@ const (
 	lockFormat = "ged_L:%s"
 )
 
+const (
+	ListLeft   = `LEFT`
+	ListRight  = `RIGHT`
+	ListBefore = `BEFORE`
+	ListAfter  = `AFTER`
+)
+
 var (
 	delLockScript = redigo.NewScript(1, `if redis.call('get', KEYS[1]) == ARGV[1]
             then
@@ -30,7 +40,22 @@ var (
 
 type myPool struct {
 	pool *redigo.Pool
-	id   []byte
+	//阻塞命令使用的连接池，避免长时间占用pool的连接
+	blockPool   *redigo.Pool
+	id          []byte
+	readTimeout time.Duration
+	ctx         context.Context
+	hooks       *hooks
+	counter     *counter
+	logger      *poolLogger
+	retry       *retryPolicy
+	breaker     *breaker
+	replicas    *replicaSet
+	//非nil时为集群，命令按slot路由到各节点
+	cluster *clusterClient
+	//配置哨兵时共享的sentinel，Close时释放
+	sentinel    *sentinel
+	releaseOnce *sync.Once
 }
 
 // NewPoolWithJson 实例化Pool
@@ -41,58 +66,93 @@ func NewPoolWithJson(jsonStr string) (p Pool, err error) {
 		return
 	}
 
+	if _, err = option.resolveURL(); err != nil {
+		return
+	}
+
 	return NewPool(option), err
 }
 
-// NewPool 实例化Pool
+// NewPool 实例化Pool，option.URL解析失败时获取连接返回该错误
 func NewPool(option Option) (p Pool) {
-	var dialOptions = []redigo.DialOption{
-		redigo.DialDatabase(int(option.Db)),
-		redigo.DialConnectTimeout(time.Millisecond * time.Duration(option.ConnectTimeout)),
-		redigo.DialReadTimeout(time.Millisecond * time.Duration(option.ReadTimeout)),
-		redigo.DialWriteTimeout(time.Millisecond * time.Duration(option.WriteTimeout)),
-	}
-
-	if len(option.Auth) > 0 {
-		dialOptions = append(dialOptions, redigo.DialPassword(option.Auth))
-	}
-
-	addr := fmt.Sprintf("%s:%d", option.Host, option.Port)
-	id := fmt.Sprintf("%s:%d-%d", option.Host, option.Port, option.Index)
-
-	pl := &redigo.Pool{
-		MaxIdle:   option.MaxIdle,
-		MaxActive: option.MaxActive,
-		Wait:      option.Wait,
-		Dial: func() (redigo.Conn, error) {
-			return redigo.Dial("tcp", addr, dialOptions...)
-		},
-		TestOnBorrow: func(c redigo.Conn, t time.Time) error {
-			if time.Since(t) < time.Minute {
-				return nil
-			}
-			_, err := c.Do("PING")
-			if err != nil {
-				Error("PING redis failed",
-					zaplogger.Addr(id),
-					zaplogger.Error(err),
-				)
-			}
-			return err
-		},
+	option, urlErr := option.resolveURL()
+	dial := newDialer(option, time.Millisecond*time.Duration(option.ReadTimeout))
+	id := option.id()
+	logger := newPoolLogger(option)
+	testInterval := option.testOnBorrowInterval()
+
+	var st *sentinel
+	if option.Sentinel.enabled() {
+		st = acquireSentinel(option)
 	}
 
-	if option.MaxConnLifetimeSecond > 0 {
-		pl.MaxConnLifetime = time.Second * time.Duration(option.MaxConnLifetimeSecond)
-	}
+	newPool := func() *redigo.Pool {
+		pl := &redigo.Pool{
+			MaxIdle:   option.MaxIdle,
+			MaxActive: option.MaxActive,
+			Wait:      option.Wait,
+			Dial: func() (redigo.Conn, error) {
+				if urlErr != nil {
+					return nil, urlErr
+				}
+
+				addr, err := option.address()
+				if err != nil {
+					return nil, err
+				}
+
+				conn, err := dial(addr)
+				if err != nil {
+					return nil, err
+				}
+
+				if st != nil {
+					conn = &sentinelConn{Conn: conn, addr: addr}
+				}
+				return newManagedConn(conn), nil
+			},
+			TestOnBorrow: func(c redigo.Conn, t time.Time) error {
+				if sc, ok := unmanaged(c).(*sentinelConn); ok && sc.addr != st.current() {
+					return ErrMasterSwitched
+				}
+
+				if testInterval == 0 || time.Since(t) < testInterval {
+					return nil
+				}
+				_, err := c.Do("PING")
+				if err != nil {
+					logger.log(zapcore.ErrorLevel, msgPingFailed,
+						zaplogger.Addr(id),
+						zaplogger.Error(err),
+					)
+				}
+				return err
+			},
+		}
 
-	if option.IdleTimeoutSecond > 0 {
-		pl.IdleTimeout = time.Second * time.Duration(option.IdleTimeoutSecond)
+		if option.MaxConnLifetimeSecond > 0 {
+			pl.MaxConnLifetime = time.Second * time.Duration(option.MaxConnLifetimeSecond)
+		}
+
+		if option.IdleTimeoutSecond > 0 {
+			pl.IdleTimeout = time.Second * time.Duration(option.IdleTimeoutSecond)
+		}
+		return pl
 	}
 
 	return &myPool{
-		pool: pl,
-		id:   []byte(id),
+		pool:        newPool(),
+		blockPool:   newPool(),
+		id:          []byte(id),
+		readTimeout: time.Millisecond * time.Duration(option.ReadTimeout),
+		hooks:       &hooks{},
+		counter:     &counter{},
+		logger:      logger,
+		retry:       newRetryPolicy(option.Retry),
+		breaker:     newBreaker(option, logger),
+		replicas:    newReplicaSet(option),
+		sentinel:    st,
+		releaseOnce: &sync.Once{},
 	}
 }
 
@@ -100,6 +160,10 @@ func (mp *myPool) HashCode() uint32 {
 	return crc32.ChecksumIEEE(mp.id)
 }
 
+func (mp *myPool) Id() string {
+	return base.Bytes2String(mp.id)
+}
+
 func (mp *myPool) ActiveCount() (num int) {
 	return mp.pool.ActiveCount()
 }
@@ -113,24 +177,65 @@ func (mp *myPool) Stats() redigo.PoolStats {
 }
 
 func (mp *myPool) Close() (err error) {
+	if mp.sentinel != nil {
+		mp.releaseOnce.Do(mp.sentinel.release)
+	}
+
+	if mp.blockPool != nil {
+		_ = mp.blockPool.Close()
+	}
+
+	if err = mp.replicas.close(); err != nil {
+		_ = mp.pool.Close()
+		return err
+	}
 	return mp.pool.Close()
 }
 
 func (mp *myPool) Do(cmd string, args ...interface{}) (reply interface{}, err error) {
-	start := time.Now()
-	r := mp.pool.Get()
-	defer r.Close()
+	return mp.DoCtx(mp.getCtx(), cmd, args...)
+}
 
-	reply, err = r.Do(cmd, args...)
+// DoCtx 执行redis命令，获取连接与读写均受ctx的超时与取消控制
+func (mp *myPool) DoCtx(ctx context.Context, cmd string, args ...interface{}) (reply interface{}, err error) {
+	if mp.hooks.empty() {
+		return mp.do(ctx, cmd, args...)
+	}
 
-	if err != nil {
-		Error("do redis cmd failed",
-			zaplogger.Addr(base.Bytes2String(mp.id)),
-			zaplogger.Error(err),
-			zaplogger.Cmd(cmd),
-			zaplogger.Args(args...),
-			zaplogger.Duration(time.Since(start)),
-		)
+	event := &HookEvent{
+		Kind:  HookCmd,
+		Id:    base.Bytes2String(mp.id),
+		Cmd:   cmd,
+		Args:  args,
+		Start: time.Now(),
+	}
+	return mp.hooks.process(ctx, event, func(ctx context.Context) (interface{}, error) {
+		return mp.do(ctx, cmd, args...)
+	})
+}
+
+func (mp *myPool) do(ctx context.Context, cmd string, args ...interface{}) (reply interface{}, err error) {
+	if mp.cluster != nil {
+		return mp.cluster.do(ctx, cmd, args...)
+	}
+
+	if r := mp.replicas.route(ctx, cmd); r != nil {
+		start := time.Now()
+		reply, err = r.pool.do(ctx, cmd, args...)
+		r.observe(time.Since(start), err)
+		return
+	}
+
+	start := time.Now()
+	reply, err = mp.withRetry(ctx, mp.retry.enabled(ctx, Cmd{cmd: cmd, args: args}), func() (interface{}, error) {
+		return mp.withConn(ctx, func(conn redigo.Conn) (interface{}, error) {
+			return conn.Do(cmd, args...)
+		})
+	})
+
+	// 集群重定向由上层处理，不记录错误，阻塞命令只记录错误
+	if _, _, redirect := parseRedirect(err); !redirect && (err != nil || !isBlocking(ctx)) {
+		mp.logger.cmd(mp.Id(), cmd, args, err, time.Since(start))
 	}
 	return
 }
@@ -238,6 +343,10 @@ func (mp *myPool) Get(key string) (val string, err error) {
 	return redigo.String(mp.Do("GET", key))
 }
 
+func (mp *myPool) GetCtx(ctx context.Context, key string) (val string, err error) {
+	return mp.bind(ctx).Get(key)
+}
+
 func (mp *myPool) GetBytes(key string) (val []byte, err error) {
 	return redigo.Bytes(mp.Do("GET", key))
 }
@@ -337,6 +446,10 @@ func (mp *myPool) Set(key string, value interface{}, args ...interface{}) (ok bo
 	return res == Ok, err
 }
 
+func (mp *myPool) SetCtx(ctx context.Context, key string, value interface{}, args ...interface{}) (ok bool, err error) {
+	return mp.bind(ctx).Set(key, value, args...)
+}
+
 func (mp *myPool) SetEx(key string, seconds int, value interface{}) (ok bool, err error) {
 	var res string
 	res, err = redigo.String(mp.Do("SETEX", key, seconds, value))
@@ -515,6 +628,23 @@ func (mp *myPool) HLen(key string) (length int, err error) {
 
 //endregion
 
+func lPosArgs(key string, element interface{}, rank, count, maxLen int) []interface{} {
+	args := make([]interface{}, 0, 8)
+	args = append(args, key, element)
+	if rank != 0 {
+		args = append(args, "RANK", rank)
+	}
+
+	if count >= 0 {
+		args = append(args, "COUNT", count)
+	}
+
+	if maxLen > 0 {
+		args = append(args, "MAXLEN", maxLen)
+	}
+	return args
+}
+
 //region 1.3 List
 
 func (mp *myPool) LLen(key string) (listLength int, err error) {
@@ -579,6 +709,76 @@ func (mp *myPool) RPop(key string) (value string, err error) {
 	return String(mp.Do("RPOP", key))
 }
 
+// LMove redis命令，srcSide与dstSide为ListLeft或ListRight，source为空时返回空字符串
+func (mp *myPool) LMove(source, destination, srcSide, dstSide string) (value string, err error) {
+	return String(mp.Do("LMOVE", source, destination, srcSide, dstSide))
+}
+
+// LPos redis命令，rank与maxLen为0时不指定，不存在时返回-1
+func (mp *myPool) LPos(key string, element interface{}, rank, maxLen int) (index int, err error) {
+	index, err = redigo.Int(mp.Do("LPOS", lPosArgs(key, element, rank, -1, maxLen)...))
+	if err == redigo.ErrNil {
+		return -1, nil
+	}
+	return
+}
+
+// LPosCount redis命令，count为0时返回全部匹配的位置
+func (mp *myPool) LPosCount(key string, element interface{}, rank, count, maxLen int) (indexes []int, err error) {
+	return redigo.Ints(mp.Do("LPOS", lPosArgs(key, element, rank, count, maxLen)...))
+}
+
+// LInsert redis命令，position为ListBefore或ListAfter，pivot不存在时返回-1
+func (mp *myPool) LInsert(key, position string, pivot, value interface{}) (listLength int, err error) {
+	return redigo.Int(mp.Do("LINSERT", key, position, pivot, value))
+}
+
+// LRem redis命令
+func (mp *myPool) LRem(key string, count int, value interface{}) (removeNum int, err error) {
+	return redigo.Int(mp.Do("LREM", key, count, value))
+}
+
+// RPopLPush redis命令
+func (mp *myPool) RPopLPush(source, destination string) (value string, err error) {
+	return String(mp.Do("RPOPLPUSH", source, destination))
+}
+
+// BLPop redis命令，timeout为阻塞时长，单位ms，0表示一直阻塞，超时返回空字符串
+func (mp *myPool) BLPop(timeout int, keys ...string) (key, value string, err error) {
+	ctx, seconds := mp.listBlock(timeout)
+	return keyValue(mp.DoCtx(ctx, "BLPOP", blockArgs(keys, seconds)...))
+}
+
+// BRPop redis命令
+func (mp *myPool) BRPop(timeout int, keys ...string) (key, value string, err error) {
+	ctx, seconds := mp.listBlock(timeout)
+	return keyValue(mp.DoCtx(ctx, "BRPOP", blockArgs(keys, seconds)...))
+}
+
+// BLMove redis命令，需要redis6.2以上
+func (mp *myPool) BLMove(source, destination, srcSide, dstSide string, timeout int) (value string, err error) {
+	ctx, seconds := mp.listBlock(timeout)
+	return String(mp.DoCtx(ctx, "BLMOVE", source, destination, srcSide, dstSide, seconds))
+}
+
+// BRPopLPush redis命令
+func (mp *myPool) BRPopLPush(source, destination string, timeout int) (value string, err error) {
+	ctx, seconds := mp.listBlock(timeout)
+	return String(mp.DoCtx(ctx, "BRPOPLPUSH", source, destination, seconds))
+}
+
+// BZPopMin redis命令
+func (mp *myPool) BZPopMin(timeout int, keys ...string) (key, member, score string, err error) {
+	ctx, seconds := mp.listBlock(timeout)
+	return keyMemberScore(mp.DoCtx(ctx, "BZPOPMIN", blockArgs(keys, seconds)...))
+}
+
+// BZPopMax redis命令
+func (mp *myPool) BZPopMax(timeout int, keys ...string) (key, member, score string, err error) {
+	ctx, seconds := mp.listBlock(timeout)
+	return keyMemberScore(mp.DoCtx(ctx, "BZPOPMAX", blockArgs(keys, seconds)...))
+}
+
 //endregion
 
 //region 1.4 Set
@@ -730,6 +930,7 @@ func (mp *myPool) ZCount(key string, minScore, maxScore interface{}) (count int,
 	return redigo.Int(mp.Do("ZCOUNT", key, minScore, maxScore))
 }
 
+// ZIncrBy 返回字符串分数，保留用于兼容，建议使用ZIncrByFloat
 func (mp *myPool) ZIncrBy(key string, increment interface{}, member string) (newScore string, err error) {
 	return String(mp.Do("ZINCRBY", key, increment, member))
 }
@@ -742,10 +943,12 @@ func (mp *myPool) ZRevRange(key string, startIndex, stopIndex int) (members []st
 	return redigo.Strings(mp.Do("ZREVRANGE", key, startIndex, stopIndex))
 }
 
+// ZRangeWithScore 返回的map不保留顺序，保留用于兼容，建议使用ZRangeWithScores
 func (mp *myPool) ZRangeWithScore(key string, startIndex, stopIndex int) (members map[string]string, err error) {
 	return redigo.StringMap(mp.Do("ZRANGE", key, startIndex, stopIndex, "WITHSCORES"))
 }
 
+// ZRevRangeWithScore 返回的map不保留顺序，保留用于兼容，建议使用ZRevRangeWithScores
 func (mp *myPool) ZRevRangeWithScore(key string, startIndex, stopIndex int) (members map[string]string, err error) {
 	return redigo.StringMap(mp.Do("ZREVRANGE", key, startIndex, stopIndex, "WITHSCORES"))
 }
@@ -764,6 +967,7 @@ func (mp *myPool) ZRevRangeByScore(key string, maxScore, minScore interface{}, o
 	return redigo.Strings(mp.Do("ZREVRANGEBYSCORE", key, maxScore, minScore, "LIMIT", offset, limit))
 }
 
+// ZRangeByScoreWithScore 返回的map不保留顺序，保留用于兼容，建议使用ZRangeByScoreWithScores
 func (mp *myPool) ZRangeByScoreWithScore(key string, minScore, maxScore interface{}, offset, limit int) (members map[string]string, err error) {
 	if limit == 0 {
 		return redigo.StringMap(mp.Do("ZRANGEBYSCORE", key, minScore, maxScore, "WITHSCORES"))
@@ -771,6 +975,7 @@ func (mp *myPool) ZRangeByScoreWithScore(key string, minScore, maxScore interfac
 	return redigo.StringMap(mp.Do("ZRANGEBYSCORE", key, minScore, maxScore, "WITHSCORES", "LIMIT", offset, limit))
 }
 
+// ZRevRangeByScoreWithScore 返回的map不保留顺序，保留用于兼容，建议使用ZRevRangeByScoreWithScores
 func (mp *myPool) ZRevRangeByScoreWithScore(key string, maxScore, minScore interface{}, offset, limit int) (members map[string]string, err error) {
 	if limit == 0 {
 		return redigo.StringMap(mp.Do("ZREVRANGEBYSCORE", key, maxScore, minScore, "WITHSCORES"))
@@ -786,6 +991,7 @@ func (mp *myPool) ZRevRank(key, member string) (rankIndex int, err error) {
 	return redigo.Int(mp.Do("ZREVRANK", key, member))
 }
 
+// ZScore 返回字符串分数，保留用于兼容，建议使用ZScoreFloat
 func (mp *myPool) ZScore(key, member string) (score string, err error) {
 	return String(mp.Do("ZSCORE", key, member))
 }
@@ -796,7 +1002,7 @@ func (mp *myPool) ZRem(key string, members ...interface{}) (removeNum int, err e
 	)
 	args = append(args, key)
 	args = append(args, members...)
-	return redigo.Int(mp.Do("ZREM", args))
+	return redigo.Int(mp.Do("ZREM", args...))
 }
 
 func (mp *myPool) ZRemRangeByRank(key string, startIndex, stopIndex int) (removeNum int, err error) {
@@ -830,6 +1036,130 @@ func (mp *myPool) ZScan(key string, cursor int, match string, count int) (newCur
 	return
 }
 
+// ZAddMembers redis命令，flags为ZAddNx、ZAddXx、ZAddGt、ZAddLt、ZAddCh，返回新增数，指定ZAddCh时返回变更数
+func (mp *myPool) ZAddMembers(key string, members []ZMember, flags ...ZAddFlag) (num int, err error) {
+	return redigo.Int(mp.Do("ZADD", zAddArgs(key, flags, false, members)...))
+}
+
+// ZAddIncr redis命令，ZADD INCR，因NX、XX等条件未执行时ok为false
+func (mp *myPool) ZAddIncr(key string, member string, increment float64, flags ...ZAddFlag) (newScore float64, ok bool, err error) {
+	newScore, err = redigo.Float64(mp.Do("ZADD", zAddArgs(key, flags, true, []ZMember{{Member: member, Score: increment}})...))
+	if err == redigo.ErrNil {
+		return 0, false, nil
+	}
+	return newScore, err == nil, err
+}
+
+// ZPopMin redis命令
+func (mp *myPool) ZPopMin(key string, count int) (members []ZMember, err error) {
+	return ZMembers(mp.Do("ZPOPMIN", zPopArgs(key, count)...))
+}
+
+// ZPopMax redis命令
+func (mp *myPool) ZPopMax(key string, count int) (members []ZMember, err error) {
+	return ZMembers(mp.Do("ZPOPMAX", zPopArgs(key, count)...))
+}
+
+// ZRangeByLex redis命令，min与max格式为[a、(a、-、+，limit为0时不分页
+func (mp *myPool) ZRangeByLex(key, min, max string, offset, limit int) (members []string, err error) {
+	return redigo.Strings(mp.Do("ZRANGEBYLEX", zLexArgs(key, min, max, offset, limit)...))
+}
+
+// ZRevRangeByLex redis命令
+func (mp *myPool) ZRevRangeByLex(key, max, min string, offset, limit int) (members []string, err error) {
+	return redigo.Strings(mp.Do("ZREVRANGEBYLEX", zLexArgs(key, max, min, offset, limit)...))
+}
+
+// ZLexCount redis命令
+func (mp *myPool) ZLexCount(key, min, max string) (count int, err error) {
+	return redigo.Int(mp.Do("ZLEXCOUNT", key, min, max))
+}
+
+// ZRemRangeByLex redis命令
+func (mp *myPool) ZRemRangeByLex(key, min, max string) (removeNum int, err error) {
+	return redigo.Int(mp.Do("ZREMRANGEBYLEX", key, min, max))
+}
+
+// ZUnionStore redis命令，weights为空时权重均为1，aggregate为AggregateSum、AggregateMin或AggregateMax，为空时使用SUM
+func (mp *myPool) ZUnionStore(destination string, keys []string, weights []float64, aggregate string) (memberCount int, err error) {
+	return redigo.Int(mp.Do("ZUNIONSTORE", zStoreArgs(destination, keys, weights, aggregate)...))
+}
+
+// ZInterStore redis命令
+func (mp *myPool) ZInterStore(destination string, keys []string, weights []float64, aggregate string) (memberCount int, err error) {
+	return redigo.Int(mp.Do("ZINTERSTORE", zStoreArgs(destination, keys, weights, aggregate)...))
+}
+
+// ZDiff redis命令，需要redis6.2以上
+func (mp *myPool) ZDiff(keys ...string) (members []string, err error) {
+	return redigo.Strings(mp.Do("ZDIFF", zStoreArgs("", keys, nil, "")...))
+}
+
+// ZDiffWithScores redis命令
+func (mp *myPool) ZDiffWithScores(keys ...string) (members []ZMember, err error) {
+	return ZMembers(mp.Do("ZDIFF", append(zStoreArgs("", keys, nil, ""), "WITHSCORES")...))
+}
+
+// ZDiffStore redis命令
+func (mp *myPool) ZDiffStore(destination string, keys ...string) (memberCount int, err error) {
+	return redigo.Int(mp.Do("ZDIFFSTORE", zStoreArgs(destination, keys, nil, "")...))
+}
+
+// ZMScore redis命令，需要redis6.2以上，成员不存在时对应位置为nil
+func (mp *myPool) ZMScore(key string, members ...string) (scores []*float64, err error) {
+	return Float64Ptrs(mp.Do("ZMSCORE", keysArgs(key, members)...))
+}
+
+// ZRandMember redis命令，需要redis6.2以上，count为负数时可能返回重复成员
+func (mp *myPool) ZRandMember(key string, count int) (members []string, err error) {
+	return redigo.Strings(mp.Do("ZRANDMEMBER", key, count))
+}
+
+// ZRandMemberWithScores redis命令
+func (mp *myPool) ZRandMemberWithScores(key string, count int) (members []ZMember, err error) {
+	return ZMembers(mp.Do("ZRANDMEMBER", key, count, "WITHSCORES"))
+}
+
+// ZIncrByFloat redis命令
+func (mp *myPool) ZIncrByFloat(key string, increment float64, member string) (newScore float64, err error) {
+	return redigo.Float64(mp.Do("ZINCRBY", key, increment, member))
+}
+
+// ZScoreFloat redis命令，成员不存在时exists为false
+func (mp *myPool) ZScoreFloat(key, member string) (score float64, exists bool, err error) {
+	scorePtr, err := Float64Ptr(mp.Do("ZSCORE", key, member))
+	if err != nil || scorePtr == nil {
+		return 0, false, err
+	}
+	return *scorePtr, true, nil
+}
+
+// ZRangeWithScores redis命令，按分数从小到大返回
+func (mp *myPool) ZRangeWithScores(key string, startIndex, stopIndex int) (members []ZMember, err error) {
+	return ZMembers(mp.Do("ZRANGE", key, startIndex, stopIndex, "WITHSCORES"))
+}
+
+// ZRevRangeWithScores redis命令，按分数从大到小返回
+func (mp *myPool) ZRevRangeWithScores(key string, startIndex, stopIndex int) (members []ZMember, err error) {
+	return ZMembers(mp.Do("ZREVRANGE", key, startIndex, stopIndex, "WITHSCORES"))
+}
+
+// ZRangeByScoreWithScores redis命令，limit为0时不分页
+func (mp *myPool) ZRangeByScoreWithScores(key string, minScore, maxScore interface{}, offset, limit int) (members []ZMember, err error) {
+	if limit == 0 {
+		return ZMembers(mp.Do("ZRANGEBYSCORE", key, minScore, maxScore, "WITHSCORES"))
+	}
+	return ZMembers(mp.Do("ZRANGEBYSCORE", key, minScore, maxScore, "WITHSCORES", "LIMIT", offset, limit))
+}
+
+// ZRevRangeByScoreWithScores redis命令，limit为0时不分页
+func (mp *myPool) ZRevRangeByScoreWithScores(key string, maxScore, minScore interface{}, offset, limit int) (members []ZMember, err error) {
+	if limit == 0 {
+		return ZMembers(mp.Do("ZREVRANGEBYSCORE", key, maxScore, minScore, "WITHSCORES"))
+	}
+	return ZMembers(mp.Do("ZREVRANGEBYSCORE", key, maxScore, minScore, "WITHSCORES", "LIMIT", offset, limit))
+}
+
 //endregion
 
 //region 1.7 Pub/Sub
@@ -847,24 +1177,46 @@ func (mp *myPool) PubSubChannels(pattern string) (channels []string, err error)
 //region 1.8 Script
 
 func (mp *myPool) EvalOrSha(script *redigo.Script, keysAndArgs ...interface{}) (reply interface{}, err error) {
-	r := mp.pool.Get()
-	defer r.Close()
+	return mp.EvalOrShaCtx(mp.getCtx(), script, keysAndArgs...)
+}
+
+// EvalOrShaCtx 执行lua脚本，获取连接与读写均受ctx的超时与取消控制
+func (mp *myPool) EvalOrShaCtx(ctx context.Context, script *redigo.Script, keysAndArgs ...interface{}) (reply interface{}, err error) {
+	if mp.hooks.empty() {
+		return mp.evalOrSha(ctx, script, keysAndArgs...)
+	}
 
-	return script.Do(r, keysAndArgs...)
+	event := &HookEvent{
+		Kind:   HookScript,
+		Id:     base.Bytes2String(mp.id),
+		Cmd:    "EVALSHA",
+		Args:   keysAndArgs,
+		Script: script,
+		Start:  time.Now(),
+	}
+	return mp.hooks.process(ctx, event, func(ctx context.Context) (interface{}, error) {
+		return mp.evalOrSha(ctx, script, keysAndArgs...)
+	})
 }
 
-func (mp *myPool) EvalOrSha4Int64(script *redigo.Script, keysAndArgs ...interface{}) (res int64, err error) {
-	r := mp.pool.Get()
-	defer r.Close()
+func (mp *myPool) evalOrSha(ctx context.Context, script *redigo.Script, keysAndArgs ...interface{}) (reply interface{}, err error) {
+	if mp.cluster != nil {
+		return mp.cluster.evalOrSha(ctx, script, keysAndArgs...)
+	}
 
-	return redigo.Int64(script.Do(r, keysAndArgs...))
+	return mp.withRetry(ctx, mp.retry.enabled(ctx, Cmd{cmd: "EVALSHA"}), func() (interface{}, error) {
+		return mp.withConn(ctx, func(conn redigo.Conn) (interface{}, error) {
+			return script.Do(conn, keysAndArgs...)
+		})
+	})
 }
 
-func (mp *myPool) EvalOrSha4String(script *redigo.Script, keysAndArgs ...interface{}) (res string, err error) {
-	r := mp.pool.Get()
-	defer r.Close()
+func (mp *myPool) EvalOrSha4Int64(script *redigo.Script, keysAndArgs ...interface{}) (res int64, err error) {
+	return redigo.Int64(mp.EvalOrSha(script, keysAndArgs...))
+}
 
-	return String(script.Do(r, keysAndArgs...))
+func (mp *myPool) EvalOrSha4String(script *redigo.Script, keysAndArgs ...interface{}) (res string, err error) {
+	return String(mp.EvalOrSha(script, keysAndArgs...))
 }
 
 //endregion
@@ -872,43 +1224,76 @@ func (mp *myPool) EvalOrSha4String(script *redigo.Script, keysAndArgs ...interfa
 //region 1.9 Transaction
 
 func (mp *myPool) Exec(multi Multi) (values []interface{}, err error) {
-	start := time.Now()
-	r := mp.pool.Get()
+	return mp.ExecCtx(mp.getCtx(), multi)
+}
 
-	defer func() {
+// ExecCtx 执行pipeline或事务，获取连接与读写均受ctx的超时与取消控制
+func (mp *myPool) ExecCtx(ctx context.Context, multi Multi) (values []interface{}, err error) {
+	if err = multiErr(multi); err != nil {
 		ReleaseMulti(multi)
-		_ = r.Close()
-	}()
+		return nil, err
+	}
 
-	if multi.Kind() == Transaction {
-		_ = r.Send("MULTI")
+	if mp.hooks.empty() {
+		return mp.exec(ctx, multi)
 	}
 
-	for _, cmd := range multi.CmdList() {
-		_ = r.Send(cmd.cmd, cmd.args...)
+	event := &HookEvent{
+		Kind:  HookPipeline,
+		Id:    base.Bytes2String(mp.id),
+		Start: time.Now(),
 	}
 
-	if multi.Kind() == Pipeline {
-		values, err = redigo.Values(r.Do(""))
-		if err != nil {
-			Error("do redis pipeline failed",
-				zaplogger.Addr(base.Bytes2String(mp.id)),
-				zaplogger.Error(err),
-				zaplogger.Duration(time.Since(start)),
-			)
-		}
-		return
+	if multi.Kind() == Transaction {
+		event.Kind = HookTransaction
 	}
 
-	values, err = redigo.Values(r.Do("EXEC"))
-	if err != nil {
-		Error("do redis multi failed",
-			zaplogger.Addr(base.Bytes2String(mp.id)),
-			zaplogger.Error(err),
-			zaplogger.Duration(time.Since(start)),
-		)
+	// multi执行后会被回收，钩子持有副本
+	event.CmdList = make([]Cmd, len(multi.CmdList()))
+	copy(event.CmdList, multi.CmdList())
+
+	executed := false
+	reply, err := mp.hooks.process(ctx, event, func(ctx context.Context) (interface{}, error) {
+		executed = true
+		return mp.exec(ctx, multi)
+	})
+
+	// 被钩子拦截时multi未执行，需在此回收
+	if !executed {
+		ReleaseMulti(multi)
+	}
+
+	values, _ = reply.([]interface{})
+	return values, err
+}
+
+func (mp *myPool) exec(ctx context.Context, multi Multi) (values []interface{}, err error) {
+	if mp.cluster != nil {
+		return mp.cluster.exec(ctx, multi)
 	}
 
+	start := time.Now()
+	defer ReleaseMulti(multi)
+
+	values, err = redigo.Values(mp.withRetry(ctx, mp.retry.enabled(ctx, multi.CmdList()...), func() (interface{}, error) {
+		return mp.withConn(ctx, func(conn redigo.Conn) (interface{}, error) {
+			if multi.Kind() == Transaction {
+				_ = conn.Send("MULTI")
+			}
+
+			for _, cmd := range multi.CmdList() {
+				_ = conn.Send(cmd.cmd, cmd.args...)
+			}
+
+			if multi.Kind() == Pipeline {
+				return conn.Do("")
+			}
+
+			return conn.Do("EXEC")
+		})
+	}))
+
+	mp.logger.multi(mp.Id(), multi.Kind(), len(multi.CmdList()), err, time.Since(start))
 	return
 }
 
@@ -929,6 +1314,10 @@ func (mp *myPool) Acquire(key string, timeoutSecond int) (token int64, err error
 	return token, nil
 }
 
+func (mp *myPool) AcquireCtx(ctx context.Context, key string, timeoutSecond int) (token int64, err error) {
+	return mp.bind(ctx).Acquire(key, timeoutSecond)
+}
+
 func (mp *myPool) Release(key string, token int64) (ok bool, err error) {
 	var (
 		suc int64
@@ -937,6 +1326,10 @@ func (mp *myPool) Release(key string, token int64) (ok bool, err error) {
 	return suc == 1, err
 }
 
+func (mp *myPool) ReleaseCtx(ctx context.Context, key string, token int64) (ok bool, err error) {
+	return mp.bind(ctx).Release(key, token)
+}
+
 //endregion
 
 //region 2.0 Server
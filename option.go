@@ -1,5 +1,17 @@
 package gedis
 
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+const (
+	NetworkTcp  = `tcp`
+	NetworkUnix = `unix`
+)
+
 type Option struct {
 	Host                  string `yaml:"host" json:"host"`
 	Port                  int    `yaml:"port" json:"port"`
@@ -18,11 +30,105 @@ type Option struct {
 	WriteTimeout int `yaml:"writeTimeout" json:"writeTimeout"`
 	//节点索引
 	Index int `yaml:"index" json:"index"`
+	//redis6 ACL用户名，为空时只使用Auth认证
+	Username string `yaml:"username" json:"username"`
+	//哨兵配置，配置后忽略Host与Port
+	Sentinel SentinelOption `yaml:"sentinel" json:"sentinel"`
+	//是否使用TLS连接
+	TLS bool `yaml:"tls" json:"tls"`
+	//客户端证书，双向认证时配置
+	TLSCertFile string `yaml:"tlsCertFile" json:"tlsCertFile"`
+	TLSKeyFile  string `yaml:"tlsKeyFile" json:"tlsKeyFile"`
+	//CA证书，为空时使用系统CA
+	TLSCaFile     string `yaml:"tlsCaFile" json:"tlsCaFile"`
+	TLSServerName string `yaml:"tlsServerName" json:"tlsServerName"`
+	//跳过证书校验，仅用于开发环境
+	TLSSkipVerify bool `yaml:"tlsSkipVerify" json:"tlsSkipVerify"`
+	//日志级别、采样、参数脱敏与慢命令配置
+	Log LogOption `yaml:"log" json:"log"`
+	//为nil时使用包级别的Debug与Error
+	Logger Logger `yaml:"-" json:"-"`
+	//重试配置，默认只重试只读与幂等命令
+	Retry RetryOption `yaml:"retry" json:"retry"`
+	//熔断配置，按失败率或慢调用比例熔断
+	Breaker BreakerOption `yaml:"breaker" json:"breaker"`
+	//从库地址host:port，只读命令发往从库，其余配置与主库相同
+	Replicas []string `yaml:"replicas" json:"replicas"`
+	//从库选择策略: roundRobin(默认)、random、latency
+	ReplicaStrategy string `yaml:"replicaStrategy" json:"replicaStrategy"`
+	//网络类型: tcp(默认)、unix，unix时Host为socket文件路径
+	Network string `yaml:"network" json:"network"`
+	//自定义拨号函数，用于代理或自定义net.Dialer，设置后ConnectTimeout不再生效
+	DialFunc func(network, addr string) (net.Conn, error) `yaml:"-" json:"-"`
+	//连接名称，实际名称为ClientName@节点标识#进程id，CLIENT LIST中可据此区分服务
+	ClientName string `yaml:"clientName" json:"clientName"`
+	//新连接建立后依次执行的命令，如"CLIENT NO-EVICT on"、"READONLY"
+	InitCmds []string `yaml:"initCmds" json:"initCmds"`
+	//空闲连接借出前PING检测的间隔，单位秒，默认60，小于0时不检测
+	TestOnBorrowSecond int `yaml:"testOnBorrowSecond" json:"testOnBorrowSecond"`
+	//连接地址，格式同ParseURL，地址与参数覆盖其他配置，便于SetPool、json与yaml配置使用URL
+	URL string `yaml:"url" json:"url"`
+}
+
+// address 获取redis地址，配置哨兵时返回当前master地址
+func (o *Option) address() (addr string, err error) {
+	if o.Sentinel.enabled() {
+		return getSentinel(*o).masterAddr()
+	}
+
+	if o.network() == NetworkUnix {
+		return o.Host, nil
+	}
+
+	return fmt.Sprintf("%s:%d", o.Host, o.Port), nil
+}
+
+func (o *Option) network() string {
+	if o.Network == "" {
+		return NetworkTcp
+	}
+	return o.Network
+}
+
+// clientName 连接名称，未配置ClientName时为空
+func (o *Option) clientName() string {
+	if o.ClientName == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s@%s#%d", o.ClientName, o.id(), os.Getpid())
+}
+
+// testOnBorrowInterval 借出前PING检测的间隔，0表示不检测
+func (o *Option) testOnBorrowInterval() time.Duration {
+	if o.TestOnBorrowSecond < 0 {
+		return 0
+	}
+
+	if o.TestOnBorrowSecond == 0 {
+		return time.Minute
+	}
+	return time.Second * time.Duration(o.TestOnBorrowSecond)
+}
+
+// id 节点标识
+func (o *Option) id() string {
+	if o.Sentinel.enabled() {
+		return fmt.Sprintf("%s-%d", o.Sentinel.MasterName, o.Index)
+	}
+
+	if o.network() == NetworkUnix {
+		return fmt.Sprintf("%s-%d", o.Host, o.Index)
+	}
+
+	return fmt.Sprintf("%s:%d-%d", o.Host, o.Port, o.Index)
 }
 
 type GroupOption struct {
 	Option       Option `yaml:"option" json:"option"`
 	VirtualCount int    `yaml:"virtualCount" json:"virtualCount"`
+	//熔断时Group.Get将key路由到下一个健康节点
+	SkipOpenCircuit bool `yaml:"skipOpenCircuit" json:"skipOpenCircuit"`
 }
 
 func DefaultOption() Option {
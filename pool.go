@@ -1,6 +1,7 @@
 package gedis
 
 import (
+	"context"
 	"sync"
 
 	redigo "github.com/garyburd/redigo/redis"
@@ -10,12 +11,23 @@ import (
 type Pool interface {
 	base.CanHash
 
+	// Id 节点标识，格式为host:port-index，哨兵时为masterName-index
+	Id() string
 	ActiveCount() (num int)
 	IdleCount() (num int)
 	Stats() redigo.PoolStats
+	// Counter 缓存命中、限流与获取连接等运行计数
+	Counter() Counter
+	// BreakerState 熔断状态，熔断时命令直接返回ErrBreakerOpen
+	BreakerState() BreakerState
 	Close() (err error)
 
 	Do(cmd string, args ...interface{}) (reply interface{}, err error)
+	DoCtx(ctx context.Context, cmd string, args ...interface{}) (reply interface{}, err error)
+	// WithContext 返回绑定ctx的Pool，通过其执行的所有命令均受ctx的超时与取消控制
+	WithContext(ctx context.Context) Pool
+	// AddHook 添加命令钩子，单个命令、pipeline、事务与lua脚本执行前后均会调用
+	AddHook(hooks ...Hook)
 
 	//-----------------Key--------------------------
 	Del(keys ...interface{}) (delNum int, err error)
@@ -37,6 +49,7 @@ type Pool interface {
 	//-----------------String--------------------------
 	Append(key string, value interface{}) (strLength int, err error)
 	Get(key string) (val string, err error)
+	GetCtx(ctx context.Context, key string) (val string, err error)
 	GetBytes(key string) (val []byte, err error)
 	MGet(keys ...string) (values []string, err error)
 	MGetMap(keys ...string) (keyValue map[string]string, err error)
@@ -44,6 +57,7 @@ type Pool interface {
 	MSet(key1 string, value1 interface{}, keyValues ...interface{}) (ok bool, err error)
 	MSetByMap(keyValues map[string]interface{}) (ok bool, err error)
 	Set(key string, value interface{}, args ...interface{}) (ok bool, err error)
+	SetCtx(ctx context.Context, key string, value interface{}, args ...interface{}) (ok bool, err error)
 	SetEx(key string, seconds int, value interface{}) (ok bool, err error)
 	SetNx(key string, value interface{}) (ok int, err error)
 	GetSet(key string, value interface{}) (oldValue string, err error)
@@ -57,6 +71,9 @@ type Pool interface {
 	SetBit(key string, offset int, bit int8) (oldBit int, err error)
 	GetBit(key string, offset int) (bit int, err error)
 	BitCount(key string, args ...interface{}) (num int, err error)
+	BitOp(operation, destKey string, keys ...string) (strLength int, err error)
+	BitPos(key string, bit int8, args ...interface{}) (position int, err error)
+	BitField(key string, bf *BitFieldArgs) (values []*int64, err error)
 
 	//-----------------Hash--------------------------
 	HSet(key string, field string, value interface{}) (isNew int, err error)
@@ -68,6 +85,9 @@ type Pool interface {
 	HMGetMap(key string, fields ...string) (keyValues map[string]string, err error)
 	HGetAll(key string) (keyValues map[string]string, err error)
 	HGetAllBytes(key string) (keyValues map[string][]byte, err error)
+	HGetAllStruct(key string, dst interface{}) (exists bool, err error)
+	HMSetStruct(key string, src interface{}) (ok bool, err error)
+	HMGetStruct(key string, dst interface{}, fields ...string) (exists bool, err error)
 	HDel(key string, fields ...string) (delNum int, err error)
 	HExists(key string, field string) (exists bool, err error)
 	HIncrBy(key string, field string, increment int) (val int64, err error)
@@ -88,6 +108,18 @@ type Pool interface {
 	RPush(key string, values ...interface{}) (listLength int, err error)
 	RPushX(key string, value interface{}) (listLength int, err error)
 	RPop(key string) (value string, err error)
+	LMove(source, destination, srcSide, dstSide string) (value string, err error)
+	LPos(key string, element interface{}, rank, maxLen int) (index int, err error)
+	LPosCount(key string, element interface{}, rank, count, maxLen int) (indexes []int, err error)
+	LInsert(key, position string, pivot, value interface{}) (listLength int, err error)
+	LRem(key string, count int, value interface{}) (removeNum int, err error)
+	RPopLPush(source, destination string) (value string, err error)
+	BLPop(timeout int, keys ...string) (key, value string, err error)
+	BRPop(timeout int, keys ...string) (key, value string, err error)
+	BLMove(source, destination, srcSide, dstSide string, timeout int) (value string, err error)
+	BRPopLPush(source, destination string, timeout int) (value string, err error)
+	BZPopMin(timeout int, keys ...string) (key, member, score string, err error)
+	BZPopMax(timeout int, keys ...string) (key, member, score string, err error)
 
 	//--------------------Set---------------------------
 	SAdd(key string, members ...interface{}) (addNum int, err error)
@@ -125,7 +157,30 @@ type Pool interface {
 	ZScore(key, member string) (score string, err error)
 	ZRem(key string, members ...interface{}) (removeNum int, err error)
 	ZRemRangeByRank(key string, startIndex, stopIndex int) (removeNum int, err error)
+	ZRemRangeByScore(key string, minScore, maxScore interface{}) (removeNum int, err error)
 	ZScan(key string, cursor int, match string, count int) (newCursor int, keys []string, err error)
+	ZAddMembers(key string, members []ZMember, flags ...ZAddFlag) (num int, err error)
+	ZAddIncr(key string, member string, increment float64, flags ...ZAddFlag) (newScore float64, ok bool, err error)
+	ZPopMin(key string, count int) (members []ZMember, err error)
+	ZPopMax(key string, count int) (members []ZMember, err error)
+	ZRangeByLex(key, min, max string, offset, limit int) (members []string, err error)
+	ZRevRangeByLex(key, max, min string, offset, limit int) (members []string, err error)
+	ZLexCount(key, min, max string) (count int, err error)
+	ZRemRangeByLex(key, min, max string) (removeNum int, err error)
+	ZUnionStore(destination string, keys []string, weights []float64, aggregate string) (memberCount int, err error)
+	ZInterStore(destination string, keys []string, weights []float64, aggregate string) (memberCount int, err error)
+	ZDiff(keys ...string) (members []string, err error)
+	ZDiffWithScores(keys ...string) (members []ZMember, err error)
+	ZDiffStore(destination string, keys ...string) (memberCount int, err error)
+	ZMScore(key string, members ...string) (scores []*float64, err error)
+	ZRandMember(key string, count int) (members []string, err error)
+	ZRandMemberWithScores(key string, count int) (members []ZMember, err error)
+	ZIncrByFloat(key string, increment float64, member string) (newScore float64, err error)
+	ZScoreFloat(key, member string) (score float64, exists bool, err error)
+	ZRangeWithScores(key string, startIndex, stopIndex int) (members []ZMember, err error)
+	ZRevRangeWithScores(key string, startIndex, stopIndex int) (members []ZMember, err error)
+	ZRangeByScoreWithScores(key string, minScore, maxScore interface{}, offset, limit int) (members []ZMember, err error)
+	ZRevRangeByScoreWithScores(key string, maxScore, minScore interface{}, offset, limit int) (members []ZMember, err error)
 
 	//----------------------Geo-----------------------------
 	GeoAdd(key string, longitude, latitude float64, member interface{}, args ...interface{}) (createNum int, err error)
@@ -136,24 +191,58 @@ type Pool interface {
 	GeoRadius(key string, longitude, latitude float64, radius interface{}, unit string, count int, sort string) (locationList []Location, err error)
 	GeoRadiusByMember(key string, member interface{}, radius interface{}, unit string, count int, sort string) (locationList []Location, err error)
 
+	//--------------------HyperLogLog---------------------------
+	PFAdd(key string, elements ...interface{}) (changed int, err error)
+	PFCount(keys ...string) (count int64, err error)
+	PFMerge(destKey string, sourceKeys ...string) (ok bool, err error)
+
+	//--------------------Stream---------------------------
+	XAdd(key, id string, trim *StreamTrim, fields map[string]interface{}) (newId string, err error)
+	XRange(key, start, end string, count int) (messages []StreamMessage, err error)
+	XRevRange(key, end, start string, count int) (messages []StreamMessage, err error)
+	XRead(count, block int, streams map[string]string) (streamList []Stream, err error)
+	XGroupCreate(key, group, id string, mkStream bool) (ok bool, err error)
+	XGroupDestroy(key, group string) (ok bool, err error)
+	XGroupSetId(key, group, id string) (ok bool, err error)
+	XGroupDelConsumer(key, group, consumer string) (pendingNum int, err error)
+	XReadGroup(group, consumer string, count, block int, noAck bool, streams map[string]string) (streamList []Stream, err error)
+	XAck(key, group string, ids ...string) (ackNum int, err error)
+	XPending(key, group string) (pending StreamPending, err error)
+	XPendingExt(key, group string, minIdle int, start, end string, count int, consumer string) (entries []StreamPendingEntry, err error)
+	XClaim(key, group, consumer string, minIdle int, ids ...string) (messages []StreamMessage, err error)
+	XAutoClaim(key, group, consumer string, minIdle int, start string, count int) (nextId string, messages []StreamMessage, err error)
+	XTrim(key string, trim StreamTrim) (removeNum int, err error)
+	XDel(key string, ids ...string) (delNum int, err error)
+	XLen(key string) (length int, err error)
+	XInfoStream(key string) (info StreamInfo, err error)
+	XInfoGroups(key string) (groups []StreamGroup, err error)
+	XInfoConsumers(key, group string) (consumers []StreamConsumer, err error)
+
 	//--------------------Pub/Sub---------------------------
 	Publish(channel string, msg string) (receiveNum int, err error)
 	PubSubChannels(pattern string) (channels []string, err error)
 
 	//--------------------Script---------------------------
 	EvalOrSha(script *redigo.Script, keysAndArgs ...interface{}) (reply interface{}, err error)
+	EvalOrShaCtx(ctx context.Context, script *redigo.Script, keysAndArgs ...interface{}) (reply interface{}, err error)
 	EvalOrSha4Int64(script *redigo.Script, keysAndArgs ...interface{}) (res int64, err error)
 	EvalOrSha4String(script *redigo.Script, keysAndArgs ...interface{}) (res string, err error)
 
 	//--------------------Transaction---------------------------
 	Exec(multi Multi) (values []interface{}, err error)
+	ExecCtx(ctx context.Context, multi Multi) (values []interface{}, err error)
 
 	//--------------------Lock/Limit/Cache---------------------------
 	Acquire(key string, timeoutSecond int) (token int64, err error)
+	AcquireCtx(ctx context.Context, key string, timeoutSecond int) (token int64, err error)
 	Release(key string, token int64) (ok bool, err error)
+	ReleaseCtx(ctx context.Context, key string, token int64) (ok bool, err error)
 	LevelCache(localCache *sync.Map, key string, current, timeoutSecond int64, handler Handler) (value []byte, err error)
+	LevelCacheCtx(ctx context.Context, localCache *sync.Map, key string, current, timeoutSecond int64, handler Handler) (value []byte, err error)
 	CacheGet(key string, current, timeoutSecond int64, handler Handler) (value []byte, err error)
+	CacheGetCtx(ctx context.Context, key string, current, timeoutSecond int64, handler Handler) (value []byte, err error)
 	CacheGetItem(key string, current, timeoutSecond int64, handler Handler) (item Item, err error)
+	CacheGetItemCtx(ctx context.Context, key string, current, timeoutSecond int64, handler Handler) (item Item, err error)
 	CacheRemove(key string) (ok bool, err error)
 	GetToken(key string, current int64, capacity, rate, reqNum, keyTimeoutSecond int) (ok bool, err error)
 	SecondLimitByToken(key string, limit int, reqNum int) (ok bool, err error)
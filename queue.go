@@ -0,0 +1,246 @@
+package gedis
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/atomic"
+)
+
+const (
+	defaultQueueWorkerTimeout = 30000
+	defaultQueueReapInterval  = 10000
+)
+
+var (
+	// reapScript worker心跳过期时将其处理中列表的任务全部移回队列，KEYS: 处理中列表、队列、worker集合、心跳key，ARGV: worker
+	reapScript = redigo.NewScript(4, `if redis.call('EXISTS', KEYS[4]) == 1 then
+                return -1
+            end
+            local num = 0
+            while redis.call('RPOPLPUSH', KEYS[1], KEYS[2]) do
+                num = num + 1
+            end
+            redis.call('SREM', KEYS[3], ARGV[1])
+            return num`)
+
+	// requeueScript 任务仍在处理中列表时才放回队列，KEYS: 处理中列表、队列，ARGV: 任务
+	requeueScript = redigo.NewScript(2, `if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+                return 0
+            end
+            redis.call('RPUSH', KEYS[2], ARGV[1])
+            return 1`)
+)
+
+// QueueOption 可靠队列配置，集群模式下Name需使用{hash tag}保证相关key在同一slot
+type QueueOption struct {
+	Name string `yaml:"name" json:"name"`
+	//worker名称，默认为hostname-pid
+	Worker string `yaml:"worker" json:"worker"`
+	//心跳超时时长，超时的worker处理中的任务会被移回队列，需大于任务的最长处理时长，单位ms，默认30000
+	WorkerTimeout int `yaml:"workerTimeout" json:"workerTimeout"`
+	//心跳间隔，单位ms，默认为WorkerTimeout的1/3
+	HeartbeatInterval int `yaml:"heartbeatInterval" json:"heartbeatInterval"`
+	//回收间隔，单位ms，默认10000
+	ReapInterval int `yaml:"reapInterval" json:"reapInterval"`
+	//为nil时使用包级别的Debug与Error
+	Logger Logger `yaml:"-" json:"-"`
+}
+
+// QueueStats 队列统计
+type QueueStats struct {
+	//等待处理的任务数
+	Pending int
+	//全部worker处理中的任务数
+	InFlight int
+	//已注册的worker数
+	Workers int
+}
+
+// Queue 基于list的可靠队列，任务出队时移入worker的处理中列表，确认后删除
+type Queue interface {
+	// Push 添加任务
+	Push(jobs ...interface{}) (length int, err error)
+	// Pop 阻塞取出任务并移入处理中列表，timeout单位ms，0表示一直阻塞，超时返回空字符串
+	Pop(timeout int) (job string, err error)
+	// Ack 确认任务，从处理中列表删除
+	Ack(job string) (ok bool, err error)
+	// Requeue 将处理中的任务放回队列，下次Pop时优先取出，任务不在处理中列表时不放回且ok为false
+	Requeue(job string) (ok bool, err error)
+	// Stats 队列统计
+	Stats() (stats QueueStats, err error)
+	// Run 阻塞执行心跳与回收，ctx取消后返回
+	Run(ctx context.Context) error
+}
+
+type queue struct {
+	pool       Pool
+	option     QueueOption
+	logger     Logger
+	processing string
+	heartbeat  string
+	workers    string
+	registered atomic.Bool
+}
+
+// NewQueue 实例化Queue，需要redis6.2以上，Pop之后需运行Run保持心跳
+func NewQueue(pool Pool, option QueueOption) Queue {
+	if option.Worker == "" {
+		hostname, _ := os.Hostname()
+		option.Worker = hostname + "-" + strconv.Itoa(os.Getpid())
+	}
+
+	if option.WorkerTimeout < 1 {
+		option.WorkerTimeout = defaultQueueWorkerTimeout
+	}
+
+	if option.HeartbeatInterval < 1 {
+		option.HeartbeatInterval = option.WorkerTimeout / 3
+	}
+
+	if option.ReapInterval < 1 {
+		option.ReapInterval = defaultQueueReapInterval
+	}
+
+	q := &queue{
+		pool:       pool,
+		option:     option,
+		logger:     option.Logger,
+		processing: processingKey(option.Name, option.Worker),
+		heartbeat:  heartbeatKey(option.Name, option.Worker),
+		workers:    option.Name + ":workers",
+	}
+
+	if q.logger == nil {
+		q.logger = defaultLogger{}
+	}
+	return q
+}
+
+func processingKey(name, worker string) string {
+	return name + ":processing:" + worker
+}
+
+func heartbeatKey(name, worker string) string {
+	return name + ":heartbeat:" + worker
+}
+
+func (q *queue) Push(jobs ...interface{}) (length int, err error) {
+	return q.pool.LPush(q.option.Name, jobs...)
+}
+
+func (q *queue) Pop(timeout int) (job string, err error) {
+	if !q.registered.Load() {
+		if err = q.beat(); err != nil {
+			return
+		}
+	}
+
+	return q.pool.BLMove(q.option.Name, q.processing, ListRight, ListLeft, timeout)
+}
+
+func (q *queue) Ack(job string) (ok bool, err error) {
+	removeNum, err := q.pool.LRem(q.processing, 1, job)
+	return removeNum > 0, err
+}
+
+func (q *queue) Requeue(job string) (ok bool, err error) {
+	num, err := q.pool.EvalOrSha4Int64(requeueScript, q.processing, q.option.Name, job)
+	return num > 0, err
+}
+
+func (q *queue) Stats() (stats QueueStats, err error) {
+	workers, err := q.pool.SMembers(q.workers)
+	if err != nil {
+		return
+	}
+
+	m := PipeMulti().LLen(q.option.Name)
+	for _, worker := range workers {
+		m.LLen(processingKey(q.option.Name, worker))
+	}
+
+	lengths, err := redigo.Ints(q.pool.Exec(m))
+	if err != nil {
+		return
+	}
+
+	stats.Pending = lengths[0]
+	stats.Workers = len(workers)
+	for _, length := range lengths[1:] {
+		stats.InFlight += length
+	}
+	return
+}
+
+func (q *queue) Run(ctx context.Context) error {
+	if err := q.beat(); err != nil {
+		return err
+	}
+
+	var (
+		heartbeat = time.NewTicker(time.Millisecond * time.Duration(q.option.HeartbeatInterval))
+		reap      = time.NewTicker(time.Millisecond * time.Duration(q.option.ReapInterval))
+	)
+
+	defer heartbeat.Stop()
+	defer reap.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if err := q.beat(); err != nil {
+				q.logger.Error(msgQueueFailed, zaplogger.Key(q.option.Name), zaplogger.Error(err))
+			}
+		case <-reap.C:
+			if err := q.reap(); err != nil {
+				q.logger.Error(msgQueueFailed, zaplogger.Key(q.option.Name), zaplogger.Error(err))
+			}
+		}
+	}
+}
+
+// beat 注册worker并刷新心跳
+func (q *queue) beat() error {
+	_, err := q.pool.Exec(PipeMulti().
+		SAdd(q.workers, q.option.Worker).
+		Set(q.heartbeat, time.Now().Unix(), "PX", q.option.WorkerTimeout),
+	)
+
+	if err == nil {
+		q.registered.Store(true)
+	}
+	return err
+}
+
+// reap 将心跳超时的worker处理中的任务移回队列
+func (q *queue) reap() error {
+	workers, err := q.pool.SMembers(q.workers)
+	if err != nil {
+		return err
+	}
+
+	for _, worker := range workers {
+		if worker == q.option.Worker {
+			continue
+		}
+
+		num, err := q.pool.EvalOrSha4Int64(reapScript,
+			processingKey(q.option.Name, worker), q.option.Name, q.workers, heartbeatKey(q.option.Name, worker), worker,
+		)
+		if err != nil {
+			return err
+		}
+
+		if num > 0 {
+			q.logger.Warn(msgQueueReaped, zaplogger.Key(q.option.Name), zaplogger.String("Worker", worker), zaplogger.Int64("Num", num))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package gedis
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+const (
+	ReplicaRoundRobin = `roundRobin`
+	ReplicaRandom     = `random`
+	ReplicaLatency    = `latency`
+)
+
+var (
+	// readOnlyCmds 可以发往从库的只读命令
+	readOnlyCmds = map[string]bool{
+		"GET": true, "MGET": true, "GETRANGE": true, "STRLEN": true, "GETBIT": true, "BITCOUNT": true, "BITPOS": true,
+		"EXISTS": true, "TTL": true, "PTTL": true, "TYPE": true, "KEYS": true, "SCAN": true, "DUMP": true, "RANDOMKEY": true,
+		"HGET": true, "HMGET": true, "HGETALL": true, "HKEYS": true, "HVALS": true, "HLEN": true, "HEXISTS": true, "HSCAN": true, "HSTRLEN": true,
+		"LLEN": true, "LINDEX": true, "LRANGE": true, "LPOS": true,
+		"SMEMBERS": true, "SISMEMBER": true, "SMISMEMBER": true, "SCARD": true, "SRANDMEMBER": true, "SSCAN": true,
+		"SINTER": true, "SUNION": true, "SDIFF": true,
+		"ZCARD": true, "ZCOUNT": true, "ZLEXCOUNT": true, "ZSCORE": true, "ZMSCORE": true, "ZRANK": true, "ZREVRANK": true,
+		"ZRANGE": true, "ZREVRANGE": true, "ZRANGEBYSCORE": true, "ZREVRANGEBYSCORE": true, "ZRANGEBYLEX": true, "ZREVRANGEBYLEX": true,
+		"ZSCAN": true, "ZRANDMEMBER": true, "ZDIFF": true,
+		"GEOPOS": true, "GEODIST": true, "GEOHASH": true, "GEORADIUS_RO": true, "GEORADIUSBYMEMBER_RO": true, "GEOSEARCH": true,
+		"PFCOUNT": true, "XRANGE": true, "XREVRANGE": true, "XLEN": true,
+	}
+)
+
+type primaryKey struct{}
+
+// WithPrimary 返回的ctx执行只读命令时仍然访问主库，用于写后立即读的场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// replica 从库节点，latency为执行耗时的滑动平均值
+type replica struct {
+	pool    *myPool
+	latency atomic.Int64
+}
+
+type replicaSet struct {
+	strategy string
+	list     []*replica
+	next     atomic.Uint64
+}
+
+func newReplicaSet(option Option) *replicaSet {
+	if len(option.Replicas) == 0 {
+		return nil
+	}
+
+	rs := &replicaSet{
+		strategy: option.ReplicaStrategy,
+		list:     make([]*replica, 0, len(option.Replicas)),
+	}
+
+	for _, addr := range option.Replicas {
+		opt := option
+		opt.Replicas = nil
+		opt.Sentinel = SentinelOption{}
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			host, port = addr, strconv.Itoa(defaultPort)
+		}
+		opt.Host = host
+		opt.Port, _ = strconv.Atoi(port)
+
+		rs.list = append(rs.list, &replica{pool: NewPool(opt).(*myPool)})
+	}
+
+	return rs
+}
+
+// route 只读命令选择从库，ctx指定访问主库或从库全部熔断时返回nil
+func (rs *replicaSet) route(ctx context.Context, cmd string) *replica {
+	if rs == nil || !readOnlyCmds[strings.ToUpper(cmd)] {
+		return nil
+	}
+
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return nil
+	}
+
+	var start int
+	switch rs.strategy {
+	case ReplicaRandom:
+		start = rand.Intn(len(rs.list))
+	case ReplicaLatency:
+		return rs.fastest()
+	default:
+		start = int(rs.next.Inc() % uint64(len(rs.list)))
+	}
+
+	for offset := 0; offset < len(rs.list); offset++ {
+		r := rs.list[(start+offset)%len(rs.list)]
+		if r.pool.BreakerState() != BreakerOpen {
+			return r
+		}
+	}
+	return nil
+}
+
+// fastest 平均耗时最低的从库
+func (rs *replicaSet) fastest() (fastest *replica) {
+	for _, r := range rs.list {
+		if r.pool.BreakerState() == BreakerOpen {
+			continue
+		}
+
+		if fastest == nil || r.latency.Load() < fastest.latency.Load() {
+			fastest = r
+		}
+	}
+	return
+}
+
+// observe 按1/8权重更新耗时平均值，失败时按连接读超时计入
+func (r *replica) observe(duration time.Duration, err error) {
+	if isBreakerFailure(err) && r.pool.readTimeout > duration {
+		duration = r.pool.readTimeout
+	}
+
+	for {
+		old := r.latency.Load()
+		latency := int64(duration)
+		if old > 0 {
+			latency = old + (int64(duration)-old)/8
+		}
+
+		if r.latency.CAS(old, latency) {
+			return
+		}
+	}
+}
+
+func (rs *replicaSet) close() (err error) {
+	if rs == nil {
+		return nil
+	}
+
+	for _, r := range rs.list {
+		if er := r.pool.Close(); er != nil {
+			err = er
+		}
+	}
+	return
+}
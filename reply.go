@@ -49,6 +49,38 @@ func String(reply interface{}, err error) (string, error) {
 	return "", fmt.Errorf("redigo: unexpected type for String, got type %T", reply)
 }
 
+// keyValue 转换BLPOP、BRPOP结果，超时返回空字符串
+func keyValue(reply interface{}, err error) (string, string, error) {
+	values, err := redigo.Strings(reply, err)
+	if err != nil {
+		if err == redigo.ErrNil {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+
+	if len(values) != 2 {
+		return "", "", errors.New("redigo: expects [key, value] result")
+	}
+	return values[0], values[1], nil
+}
+
+// keyMemberScore 转换BZPOPMIN、BZPOPMAX结果，超时返回空字符串
+func keyMemberScore(reply interface{}, err error) (string, string, string, error) {
+	values, err := redigo.Strings(reply, err)
+	if err != nil {
+		if err == redigo.ErrNil {
+			return "", "", "", nil
+		}
+		return "", "", "", err
+	}
+
+	if len(values) != 3 {
+		return "", "", "", errors.New("redigo: expects [key, member, score] result")
+	}
+	return values[0], values[1], values[2], nil
+}
+
 // Locations 转换为Location信息
 func Locations(reply interface{}, err error) ([]Location, error) {
 	if err != nil {
@@ -110,3 +142,423 @@ func Positions(reply interface{}, err error) ([]Position, error) {
 
 	return positionList, nil
 }
+
+// streamMessage 解析[id, [field, value, ...]]，已删除的消息返回nil
+func streamMessage(value interface{}) (*StreamMessage, error) {
+	entry, err := redigo.Values(value, nil)
+	if err != nil {
+		if err == redigo.ErrNil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if len(entry) != 2 {
+		return nil, errors.New("redigo: StreamMessage expects two element entry")
+	}
+
+	id, err := String(entry[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry[1] == nil {
+		return nil, nil
+	}
+
+	fields, err := redigo.StringMap(entry[1], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamMessage{ID: id, Fields: fields}, nil
+}
+
+// StreamMessages 转换为StreamMessage列表，已删除的消息会被忽略
+func StreamMessages(reply interface{}, err error) ([]StreamMessage, error) {
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	messageList := make([]StreamMessage, 0, len(values))
+	for _, value := range values {
+		msg, err := streamMessage(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if msg != nil {
+			messageList = append(messageList, *msg)
+		}
+	}
+
+	return messageList, nil
+}
+
+// Streams 转换为Stream列表，阻塞读取超时无消息时返回nil
+func Streams(reply interface{}, err error) ([]Stream, error) {
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		if err == redigo.ErrNil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	streamList := make([]Stream, 0, len(values))
+	for _, value := range values {
+		val, ok := value.([]interface{})
+		if !ok || len(val) != 2 {
+			return nil, errors.New("redigo: Streams expects [key, messages] entry")
+		}
+
+		key, err := String(val[0], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		messages, err := StreamMessages(val[1], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		streamList = append(streamList, Stream{Key: key, Messages: messages})
+	}
+
+	return streamList, nil
+}
+
+// StreamPendingSummary 转换为XPENDING概要信息
+func StreamPendingSummary(reply interface{}, err error) (StreamPending, error) {
+	var pending StreamPending
+
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		return pending, err
+	}
+
+	if len(values) != 4 {
+		return pending, errors.New("redigo: StreamPendingSummary expects four values result")
+	}
+
+	if pending.Count, err = redigo.Int64(values[0], nil); err != nil {
+		return pending, err
+	}
+
+	pending.MinID, _ = String(values[1], nil)
+	pending.MaxID, _ = String(values[2], nil)
+	if values[3] == nil {
+		return pending, nil
+	}
+
+	consumers, err := redigo.Values(values[3], nil)
+	if err != nil {
+		return pending, err
+	}
+
+	pending.Consumers = make(map[string]int64, len(consumers))
+	for _, consumer := range consumers {
+		val, err := redigo.Values(consumer, nil)
+		if err != nil || len(val) != 2 {
+			return pending, errors.New("redigo: StreamPendingSummary expects [consumer, count] entry")
+		}
+
+		name, _ := String(val[0], nil)
+		pending.Consumers[name], err = redigo.Int64(val[1], nil)
+		if err != nil {
+			return pending, err
+		}
+	}
+
+	return pending, nil
+}
+
+// StreamPendingEntries 转换为XPENDING明细
+func StreamPendingEntries(reply interface{}, err error) ([]StreamPendingEntry, error) {
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StreamPendingEntry, 0, len(values))
+	for _, value := range values {
+		val, ok := value.([]interface{})
+		if !ok || len(val) != 4 {
+			return nil, errors.New("redigo: StreamPendingEntries expects four values entry")
+		}
+
+		var entry StreamPendingEntry
+		entry.ID, _ = String(val[0], nil)
+		entry.Consumer, _ = String(val[1], nil)
+		if entry.Idle, err = redigo.Int64(val[2], nil); err != nil {
+			return nil, err
+		}
+
+		if entry.DeliveryCount, err = redigo.Int64(val[3], nil); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// StreamAutoClaim 转换XAUTOCLAIM结果
+func StreamAutoClaim(reply interface{}, err error) (string, []StreamMessage, error) {
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(values) < 2 {
+		return "", nil, errors.New("redigo: StreamAutoClaim expects at least two values result")
+	}
+
+	nextId, err := String(values[0], nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	messages, err := StreamMessages(values[1], nil)
+	return nextId, messages, err
+}
+
+// streamFields 解析XINFO返回的[name, value, ...]，未知字段交由handler忽略
+func streamFields(reply interface{}, handler func(name string, value interface{}) error) error {
+	values, err := redigo.Values(reply, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(values)%2 != 0 {
+		return errors.New("redigo: XINFO expects even number of values result")
+	}
+
+	for i := 0; i < len(values); i += 2 {
+		name, err := String(values[i], nil)
+		if err != nil {
+			return err
+		}
+
+		if err = handler(name, values[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamDetail 转换为XINFO STREAM信息
+func StreamDetail(reply interface{}, err error) (StreamInfo, error) {
+	var info StreamInfo
+	if err != nil {
+		return info, err
+	}
+
+	err = streamFields(reply, func(name string, value interface{}) (err error) {
+		switch name {
+		case "length":
+			info.Length, err = redigo.Int64(value, nil)
+		case "radix-tree-keys":
+			info.RadixTreeKeys, err = redigo.Int64(value, nil)
+		case "radix-tree-nodes":
+			info.RadixTreeNodes, err = redigo.Int64(value, nil)
+		case "groups":
+			info.Groups, err = redigo.Int64(value, nil)
+		case "last-generated-id":
+			info.LastGeneratedID, err = String(value, nil)
+		case "first-entry":
+			info.FirstEntry, err = streamMessage(value)
+		case "last-entry":
+			info.LastEntry, err = streamMessage(value)
+		}
+		return
+	})
+
+	return info, err
+}
+
+// StreamGroups 转换为XINFO GROUPS信息
+func StreamGroups(reply interface{}, err error) ([]StreamGroup, error) {
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]StreamGroup, len(values))
+	for index, value := range values {
+		group := &groups[index]
+		err = streamFields(value, func(name string, value interface{}) (err error) {
+			switch name {
+			case "name":
+				group.Name, err = String(value, nil)
+			case "consumers":
+				group.Consumers, err = redigo.Int64(value, nil)
+			case "pending":
+				group.Pending, err = redigo.Int64(value, nil)
+			case "last-delivered-id":
+				group.LastDeliveredID, err = String(value, nil)
+			}
+			return
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// StreamConsumers 转换为XINFO CONSUMERS信息
+func StreamConsumers(reply interface{}, err error) ([]StreamConsumer, error) {
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	consumers := make([]StreamConsumer, len(values))
+	for index, value := range values {
+		consumer := &consumers[index]
+		err = streamFields(value, func(name string, value interface{}) (err error) {
+			switch name {
+			case "name":
+				consumer.Name, err = String(value, nil)
+			case "pending":
+				consumer.Pending, err = redigo.Int64(value, nil)
+			case "idle":
+				consumer.Idle, err = redigo.Int64(value, nil)
+			}
+			return
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return consumers, nil
+}
+
+// Int64Ptrs 转换为*int64列表，nil回复对应nil，用于BITFIELD
+func Int64Ptrs(reply interface{}, err error) ([]*int64, error) {
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*int64, len(values))
+	for index, value := range values {
+		if value == nil {
+			continue
+		}
+
+		val, err := redigo.Int64(value, nil)
+		if err != nil {
+			return nil, err
+		}
+		list[index] = &val
+	}
+
+	return list, nil
+}
+
+// ZMembers 转换为ZMember列表，保持redis返回的顺序，用于WITHSCORES、ZPOPMIN等回复
+func ZMembers(reply interface{}, err error) ([]ZMember, error) {
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values)%2 != 0 {
+		return nil, errors.New("redigo: ZMembers expects even number of values result")
+	}
+
+	members := make([]ZMember, 0, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		member, err := String(values[i], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		score, err := redigo.Float64(values[i+1], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, ZMember{Member: member, Score: score})
+	}
+
+	return members, nil
+}
+
+// Float64Ptrs 转换为*float64列表，nil回复对应nil，用于ZMSCORE
+func Float64Ptrs(reply interface{}, err error) ([]*float64, error) {
+	values, err := redigo.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*float64, len(values))
+	for index, value := range values {
+		if value == nil {
+			continue
+		}
+
+		val, err := redigo.Float64(value, nil)
+		if err != nil {
+			return nil, err
+		}
+		list[index] = &val
+	}
+
+	return list, nil
+}
+
+// Float64Ptr 转换为*float64，nil回复返回nil，用于ZSCORE、ZINCRBY
+func Float64Ptr(reply interface{}, err error) (*float64, error) {
+	if err != nil || reply == nil {
+		return nil, err
+	}
+
+	val, err := redigo.Float64(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &val, nil
+}
+
+// ScanHashStruct 将HGETALL结果写入dst，hash不存在时exists为false
+func ScanHashStruct(reply interface{}, dst interface{}) (exists bool, err error) {
+	v, err := structPointer(dst)
+	if err != nil {
+		return
+	}
+
+	values, err := BytesMap(reply, nil)
+	if err != nil || len(values) == 0 {
+		return false, err
+	}
+	return true, scanStruct(v, values)
+}
+
+// ScanHashFields 将HMGET结果按fields写入dst，fields为空时为dst的全部字段，全部字段都不存在时exists为false
+func ScanHashFields(reply interface{}, dst interface{}, fields ...string) (exists bool, err error) {
+	v, err := structPointer(dst)
+	if err != nil {
+		return
+	}
+
+	if len(fields) == 0 {
+		if fields, err = structFieldNames(dst); err != nil {
+			return
+		}
+	}
+
+	values, err := hashValues(fields, reply, nil)
+	if err != nil || len(values) == 0 {
+		return false, err
+	}
+	return true, scanStruct(v, values)
+}
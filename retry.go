@@ -0,0 +1,192 @@
+package gedis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+const (
+	defaultMinBackoff = 8
+	defaultMaxBackoff = 512
+)
+
+var (
+	// idempotentCmds 只读命令及以固定值写入、重复执行结果不变的命令，默认重试
+	idempotentCmds = map[string]bool{
+		"GET": true, "MGET": true, "GETRANGE": true, "STRLEN": true, "GETBIT": true, "BITCOUNT": true, "BITPOS": true,
+		"SET": true, "MSET": true, "SETEX": true, "PSETEX": true, "SETRANGE": true, "SETBIT": true,
+		"EXISTS": true, "TTL": true, "PTTL": true, "TYPE": true, "KEYS": true, "SCAN": true, "DUMP": true, "RANDOMKEY": true,
+		"DEL": true, "UNLINK": true, "EXPIRE": true, "PEXPIRE": true, "EXPIREAT": true, "PEXPIREAT": true, "PERSIST": true,
+		"HGET": true, "HMGET": true, "HGETALL": true, "HKEYS": true, "HVALS": true, "HLEN": true, "HEXISTS": true, "HSCAN": true, "HSTRLEN": true,
+		"HSET": true, "HMSET": true, "HDEL": true,
+		"LLEN": true, "LINDEX": true, "LRANGE": true, "LSET": true, "LPOS": true,
+		"SMEMBERS": true, "SISMEMBER": true, "SMISMEMBER": true, "SCARD": true, "SRANDMEMBER": true, "SSCAN": true,
+		"SINTER": true, "SUNION": true, "SDIFF": true, "SADD": true, "SREM": true,
+		"ZCARD": true, "ZCOUNT": true, "ZLEXCOUNT": true, "ZSCORE": true, "ZMSCORE": true, "ZRANK": true, "ZREVRANK": true,
+		"ZRANGE": true, "ZREVRANGE": true, "ZRANGEBYSCORE": true, "ZREVRANGEBYSCORE": true, "ZRANGEBYLEX": true, "ZREVRANGEBYLEX": true,
+		"ZSCAN": true, "ZREM": true, "ZREMRANGEBYSCORE": true, "ZREMRANGEBYLEX": true, "ZDIFF": true,
+		"GEOPOS": true, "GEODIST": true, "GEOHASH": true, "GEORADIUS_RO": true, "GEORADIUSBYMEMBER_RO": true, "GEOSEARCH": true,
+		"PFCOUNT": true, "XRANGE": true, "XREVRANGE": true, "XLEN": true, "XINFO": true, "XPENDING": true,
+		"PING": true, "ECHO": true, "TIME": true, "INFO": true, "DBSIZE": true,
+	}
+
+	// conditionalSetArgs SET带这些参数时结果取决于执行前的状态，不再幂等，如Acquire使用的SET NX
+	conditionalSetArgs = map[string]bool{"NX": true, "XX": true, "GET": true}
+
+	// retryableErrPrefixes 可重试的redis错误前缀
+	retryableErrPrefixes = []string{"LOADING", "TRYAGAIN", "CLUSTERDOWN", "MASTERDOWN", "READONLY"}
+)
+
+// RetryOption 重试配置，MaxAttempts不大于1时不重试
+type RetryOption struct {
+	//最大执行次数，包含首次执行
+	MaxAttempts int `yaml:"maxAttempts" json:"maxAttempts"`
+	//首次重试等待时长，单位ms，默认8ms
+	MinBackoff int `yaml:"minBackoff" json:"minBackoff"`
+	//最大等待时长，单位ms，默认512ms
+	MaxBackoff int `yaml:"maxBackoff" json:"maxBackoff"`
+}
+
+type retryKey struct{}
+
+// WithRetry 返回的ctx执行任意命令均会重试，用于Incr、LPush等非幂等命令显式开启重试
+func WithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// WithoutRetry 返回的ctx执行命令均不重试
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, false)
+}
+
+type retryPolicy struct {
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+}
+
+func newRetryPolicy(option RetryOption) *retryPolicy {
+	if option.MaxAttempts < 2 {
+		return nil
+	}
+
+	rp := &retryPolicy{
+		maxAttempts: option.MaxAttempts,
+		minBackoff:  time.Millisecond * defaultMinBackoff,
+		maxBackoff:  time.Millisecond * defaultMaxBackoff,
+	}
+
+	if option.MinBackoff > 0 {
+		rp.minBackoff = time.Millisecond * time.Duration(option.MinBackoff)
+	}
+
+	if option.MaxBackoff > 0 {
+		rp.maxBackoff = time.Millisecond * time.Duration(option.MaxBackoff)
+	}
+
+	if rp.maxBackoff < rp.minBackoff {
+		rp.maxBackoff = rp.minBackoff
+	}
+	return rp
+}
+
+// enabled 是否重试，ctx中显式设置时以ctx为准，否则只重试幂等命令
+func (rp *retryPolicy) enabled(ctx context.Context, cmds ...Cmd) bool {
+	if rp == nil {
+		return false
+	}
+
+	if retry, ok := ctx.Value(retryKey{}).(bool); ok {
+		return retry
+	}
+
+	for _, cmd := range cmds {
+		if !idempotent(cmd) {
+			return false
+		}
+	}
+	return true
+}
+
+func idempotent(cmd Cmd) bool {
+	name := strings.ToUpper(cmd.cmd)
+	if !idempotentCmds[name] {
+		return false
+	}
+
+	if name == "SET" && len(cmd.args) > 2 {
+		for _, arg := range cmd.args[2:] {
+			if conditionalSetArgs[strings.ToUpper(keyString(arg))] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// backoff 第attempt次重试的等待时长，指数增长并附加随机抖动
+func (rp *retryPolicy) backoff(attempt int) time.Duration {
+	d := rp.minBackoff << uint(attempt-1)
+	if d > rp.maxBackoff || d <= 0 {
+		d = rp.maxBackoff
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// do 执行handler，失败且错误可重试时等待后重试
+func (rp *retryPolicy) do(ctx context.Context, handler func() (interface{}, error)) (reply interface{}, err error) {
+	for attempt := 1; ; attempt++ {
+		reply, err = handler()
+		if err == nil || attempt >= rp.maxAttempts || !IsRetryable(err) {
+			return
+		}
+
+		timer := time.NewTimer(rp.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// withRetry retry为true时按重试策略执行handler
+func (mp *myPool) withRetry(ctx context.Context, retry bool, handler func() (interface{}, error)) (interface{}, error) {
+	if !retry {
+		return handler()
+	}
+	return mp.retry.do(ctx, handler)
+}
+
+// IsRetryable 网络错误、连接断开以及LOADING、TRYAGAIN、CLUSTERDOWN等redis错误可重试
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF || err == ErrMasterSwitched {
+		return true
+	}
+
+	var redisErr redigo.Error
+	if errors.As(err, &redisErr) {
+		for _, prefix := range retryableErrPrefixes {
+			if strings.HasPrefix(string(redisErr), prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
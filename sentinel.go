@@ -0,0 +1,332 @@
+package gedis
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	switchMasterChannel = `+switch-master`
+)
+
+var (
+	sentinelPingInterval = time.Second * 30
+	sentinelsMu          sync.Mutex
+	sentinels            = make(map[string]*sentinel)
+)
+
+// SentinelOption 哨兵配置，MasterName不为空时通过哨兵获取master地址
+type SentinelOption struct {
+	MasterName string   `yaml:"masterName" json:"masterName"`
+	Addrs      []string `yaml:"addrs" json:"addrs"`
+	Auth       string   `yaml:"auth" json:"auth"`
+}
+
+func (so *SentinelOption) enabled() bool {
+	return so.MasterName != "" && len(so.Addrs) > 0
+}
+
+func (so *SentinelOption) key() string {
+	return so.MasterName + "@" + strings.Join(so.Addrs, ",")
+}
+
+// sentinelConn 记录连接所属的master地址，master切换后旧连接在借出时被丢弃
+type sentinelConn struct {
+	redigo.Conn
+	addr string
+}
+
+func (sc *sentinelConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (reply interface{}, err error) {
+	return redigo.DoWithTimeout(sc.Conn, timeout, cmd, args...)
+}
+
+func (sc *sentinelConn) ReceiveWithTimeout(timeout time.Duration) (reply interface{}, err error) {
+	return redigo.ReceiveWithTimeout(sc.Conn, timeout)
+}
+
+type sentinel struct {
+	option         SentinelOption
+	connectTimeout time.Duration
+	dialFunc       func(network, addr string) (net.Conn, error)
+	mu             sync.RWMutex
+	addr           string
+	listeners      sync.Map
+	//引用计数，归零时停止订阅
+	refs    int
+	stop    chan struct{}
+	watchMu sync.Mutex
+	//当前订阅连接，停止时关闭以中断阻塞的读取
+	watchConn redigo.Conn
+	//首个使用者的日志配置
+	logger *poolLogger
+}
+
+func newSentinel(option Option) *sentinel {
+	return &sentinel{
+		option:         option.Sentinel,
+		connectTimeout: time.Millisecond * time.Duration(option.ConnectTimeout),
+		dialFunc:       option.DialFunc,
+		stop:           make(chan struct{}),
+		logger:         newPoolLogger(option),
+	}
+}
+
+// acquireSentinel 相同哨兵配置的Pool与SubConn共享同一个sentinel，首次获取时开始订阅，使用结束后需调用release
+func acquireSentinel(option Option) *sentinel {
+	sentinelsMu.Lock()
+	defer sentinelsMu.Unlock()
+
+	key := option.Sentinel.key()
+	s, ok := sentinels[key]
+	if !ok {
+		s = newSentinel(option)
+		sentinels[key] = s
+		go s.watch()
+	}
+	s.refs++
+	return s
+}
+
+// getSentinel 获取共享的sentinel，不存在时返回未订阅的临时sentinel
+func getSentinel(option Option) *sentinel {
+	sentinelsMu.Lock()
+	s, ok := sentinels[option.Sentinel.key()]
+	sentinelsMu.Unlock()
+
+	if ok {
+		return s
+	}
+	return newSentinel(option)
+}
+
+// release 释放引用，全部释放后停止订阅
+func (s *sentinel) release() {
+	sentinelsMu.Lock()
+	s.refs--
+	if s.refs > 0 {
+		sentinelsMu.Unlock()
+		return
+	}
+	delete(sentinels, s.option.key())
+	sentinelsMu.Unlock()
+
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	close(s.stop)
+	if s.watchConn != nil {
+		_ = s.watchConn.Close()
+	}
+}
+
+func (s *sentinel) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// masterAddr 获取当前master地址
+func (s *sentinel) masterAddr() (addr string, err error) {
+	s.mu.RLock()
+	addr = s.addr
+	s.mu.RUnlock()
+
+	if addr != "" {
+		return addr, nil
+	}
+
+	return s.resolve()
+}
+
+// current 获取缓存的master地址，不会访问哨兵
+func (s *sentinel) current() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.addr
+}
+
+// resolve 依次询问哨兵获取master地址
+func (s *sentinel) resolve() (addr string, err error) {
+	err = ErrNoMaster
+	for _, sentinelAddr := range s.option.Addrs {
+		addr, err = s.queryMaster(sentinelAddr)
+		if err == nil {
+			s.setAddr(addr)
+			return addr, nil
+		}
+
+		s.logger.log(zapcore.ErrorLevel, msgSentinelQueryFailed,
+			zaplogger.Addr(sentinelAddr),
+			zaplogger.String("Master", s.option.MasterName),
+			zaplogger.Error(err),
+		)
+	}
+
+	return "", err
+}
+
+func (s *sentinel) queryMaster(sentinelAddr string) (addr string, err error) {
+	conn, err := s.dial(sentinelAddr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	res, err := redigo.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", s.option.MasterName))
+	if err != nil {
+		if err == redigo.ErrNil {
+			return "", ErrNoMaster
+		}
+		return "", err
+	}
+
+	if len(res) != 2 {
+		return "", ErrNoMaster
+	}
+
+	return net.JoinHostPort(res[0], res[1]), nil
+}
+
+func (s *sentinel) dial(sentinelAddr string) (redigo.Conn, error) {
+	dialOptions := []redigo.DialOption{
+		redigo.DialConnectTimeout(s.connectTimeout),
+		redigo.DialReadTimeout(s.connectTimeout),
+		redigo.DialWriteTimeout(s.connectTimeout),
+	}
+
+	if len(s.option.Auth) > 0 {
+		dialOptions = append(dialOptions, redigo.DialPassword(s.option.Auth))
+	}
+
+	if s.dialFunc != nil {
+		dialOptions = append(dialOptions, redigo.DialNetDial(s.dialFunc))
+	}
+
+	return redigo.Dial("tcp", sentinelAddr, dialOptions...)
+}
+
+func (s *sentinel) setAddr(addr string) {
+	s.mu.Lock()
+	old := s.addr
+	s.addr = addr
+	s.mu.Unlock()
+
+	if old == "" || old == addr {
+		return
+	}
+
+	s.logger.log(zapcore.WarnLevel, msgMasterSwitched,
+		zaplogger.String("Master", s.option.MasterName),
+		zaplogger.String("From", old),
+		zaplogger.String("To", addr),
+	)
+
+	s.listeners.Range(func(_, value interface{}) bool {
+		value.(func(addr string))(addr)
+		return true
+	})
+}
+
+// onSwitch 注册master切换回调，返回值用于取消注册
+func (s *sentinel) onSwitch(handler func(addr string)) (cancel func()) {
+	key := new(byte)
+	s.listeners.Store(key, handler)
+	return func() {
+		s.listeners.Delete(key)
+	}
+}
+
+// watch 订阅哨兵的+switch-master消息，断开后轮换哨兵重连，release后退出
+func (s *sentinel) watch() {
+	for index := 0; ; index++ {
+		sentinelAddr := s.option.Addrs[index%len(s.option.Addrs)]
+		if err := s.subscribe(sentinelAddr); err != nil && !s.stopped() {
+			s.logger.log(zapcore.ErrorLevel, msgSentinelSubscribeFailed,
+				zaplogger.Addr(sentinelAddr),
+				zaplogger.String("Master", s.option.MasterName),
+				zaplogger.Error(err),
+			)
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+func (s *sentinel) subscribe(sentinelAddr string) error {
+	conn, err := s.dial(sentinelAddr)
+	if err != nil {
+		return err
+	}
+
+	s.watchMu.Lock()
+	if s.stopped() {
+		s.watchMu.Unlock()
+		return conn.Close()
+	}
+	s.watchConn = conn
+	s.watchMu.Unlock()
+
+	psc := redigo.PubSubConn{Conn: conn}
+	defer psc.Close()
+
+	if err = psc.Subscribe(switchMasterChannel); err != nil {
+		return err
+	}
+
+	// 断线期间可能错过了切换消息，重新订阅后主动查询一次
+	if addr, er := s.queryMaster(sentinelAddr); er == nil {
+		s.setAddr(addr)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		tick := time.NewTicker(sentinelPingInterval)
+		defer tick.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				if psc.Ping("hc") != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		switch msg := psc.ReceiveWithTimeout(2 * sentinelPingInterval).(type) {
+		case redigo.Message:
+			s.dealSwitch(string(msg.Data))
+		case error:
+			return msg
+		}
+	}
+}
+
+// dealSwitch 处理消息: <master name> <old ip> <old port> <new ip> <new port>
+func (s *sentinel) dealSwitch(data string) {
+	parts := strings.Fields(data)
+	if len(parts) != 5 || parts[0] != s.option.MasterName {
+		return
+	}
+
+	s.setAddr(net.JoinHostPort(parts[3], parts[4]))
+}
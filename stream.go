@@ -0,0 +1,440 @@
+package gedis
+
+import (
+	"sort"
+	"time"
+
+	redigo "github.com/garyburd/redigo/redis"
+)
+
+const (
+	StreamMaxLen = `MAXLEN`
+	StreamMinId  = `MINID`
+)
+
+// StreamMessage 消息
+type StreamMessage struct {
+	ID     string
+	Fields map[string]string
+}
+
+// Stream XREAD、XREADGROUP读取到的某个stream的消息
+type Stream struct {
+	Key      string
+	Messages []StreamMessage
+}
+
+// StreamPending XPENDING概要信息
+type StreamPending struct {
+	Count     int64
+	MinID     string
+	MaxID     string
+	Consumers map[string]int64
+}
+
+// StreamPendingEntry XPENDING明细，Idle单位ms
+type StreamPendingEntry struct {
+	ID            string
+	Consumer      string
+	Idle          int64
+	DeliveryCount int64
+}
+
+// StreamInfo XINFO STREAM信息
+type StreamInfo struct {
+	Length          int64
+	RadixTreeKeys   int64
+	RadixTreeNodes  int64
+	Groups          int64
+	LastGeneratedID string
+	FirstEntry      *StreamMessage
+	LastEntry       *StreamMessage
+}
+
+// StreamGroup XINFO GROUPS信息
+type StreamGroup struct {
+	Name            string
+	Consumers       int64
+	Pending         int64
+	LastDeliveredID string
+}
+
+// StreamConsumer XINFO CONSUMERS信息，Idle单位ms
+type StreamConsumer struct {
+	Name    string
+	Pending int64
+	Idle    int64
+}
+
+// StreamTrim 裁剪策略，XADD与XTRIM使用
+type StreamTrim struct {
+	//StreamMaxLen或StreamMinId
+	Strategy string
+	//MAXLEN时为最大长度，MINID时为最小消息id
+	Threshold interface{}
+	//近似裁剪，效率更高
+	Approx bool
+	//近似裁剪时单次最多删除的条数，0表示使用redis默认值
+	Limit int
+}
+
+// TrimMaxLen 按长度裁剪
+func TrimMaxLen(maxLen int64, approx bool) *StreamTrim {
+	return &StreamTrim{Strategy: StreamMaxLen, Threshold: maxLen, Approx: approx}
+}
+
+// TrimMinId 删除id小于minId的消息
+func TrimMinId(minId string, approx bool) *StreamTrim {
+	return &StreamTrim{Strategy: StreamMinId, Threshold: minId, Approx: approx}
+}
+
+func (st *StreamTrim) args(args []interface{}) []interface{} {
+	if st == nil {
+		return args
+	}
+
+	strategy := st.Strategy
+	if strategy == "" {
+		strategy = StreamMaxLen
+	}
+
+	if !st.Approx {
+		return append(args, strategy, st.Threshold)
+	}
+
+	args = append(args, strategy, "~", st.Threshold)
+	if st.Limit > 0 {
+		args = append(args, "LIMIT", st.Limit)
+	}
+	return args
+}
+
+func xAddArgs(key, id string, trim *StreamTrim, fields map[string]interface{}) []interface{} {
+	if id == "" {
+		id = "*"
+	}
+
+	args := make([]interface{}, 0, 2*len(fields)+6)
+	args = append(args, key)
+	args = trim.args(args)
+	args = append(args, id)
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+	return args
+}
+
+func xRangeArgs(key, start, end string, count int) []interface{} {
+	if count > 0 {
+		return []interface{}{key, start, end, "COUNT", count}
+	}
+	return []interface{}{key, start, end}
+}
+
+// xReadArgs 拼接COUNT、BLOCK与STREAMS参数，key按字典序排列
+func xReadArgs(args []interface{}, count, block int, streams map[string]string) []interface{} {
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+
+	if block > 0 {
+		args = append(args, "BLOCK", block)
+	}
+
+	keys := make([]string, 0, len(streams))
+	for key := range streams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args = append(args, "STREAMS")
+	for _, key := range keys {
+		args = append(args, key)
+	}
+
+	for _, key := range keys {
+		args = append(args, streams[key])
+	}
+	return args
+}
+
+func xReadGroupArgs(group, consumer string, count, block int, noAck bool, streams map[string]string) []interface{} {
+	args := make([]interface{}, 0, 2*len(streams)+9)
+	args = append(args, "GROUP", group, consumer)
+	if noAck {
+		args = append(args, "NOACK")
+	}
+	return xReadArgs(args, count, block, streams)
+}
+
+func xPendingExtArgs(key, group string, minIdle int, start, end string, count int, consumer string) []interface{} {
+	args := make([]interface{}, 0, 8)
+	args = append(args, key, group)
+	if minIdle > 0 {
+		args = append(args, "IDLE", minIdle)
+	}
+
+	args = append(args, start, end, count)
+	if consumer != "" {
+		args = append(args, consumer)
+	}
+	return args
+}
+
+func idArgs(args []interface{}, ids []string) []interface{} {
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return args
+}
+
+func blockDuration(block int) time.Duration {
+	return time.Millisecond * time.Duration(block)
+}
+
+//region 1.11 Stream
+
+// XAdd redis命令，id为空时由redis生成，trim为nil时不裁剪
+func (mp *myPool) XAdd(key, id string, trim *StreamTrim, fields map[string]interface{}) (newId string, err error) {
+	return String(mp.Do("XADD", xAddArgs(key, id, trim, fields)...))
+}
+
+// XRange redis命令，count为0时不限制条数
+func (mp *myPool) XRange(key, start, end string, count int) (messages []StreamMessage, err error) {
+	return StreamMessages(mp.Do("XRANGE", xRangeArgs(key, start, end, count)...))
+}
+
+// XRevRange redis命令
+func (mp *myPool) XRevRange(key, end, start string, count int) (messages []StreamMessage, err error) {
+	return StreamMessages(mp.Do("XREVRANGE", xRangeArgs(key, end, start, count)...))
+}
+
+// XRead redis命令，streams为key与起始id，block为阻塞时长，单位ms，0表示不阻塞，超时无消息时返回nil
+func (mp *myPool) XRead(count, block int, streams map[string]string) (streamList []Stream, err error) {
+	ctx := withBlock(mp.getCtx(), blockDuration(block))
+	return Streams(mp.DoCtx(ctx, "XREAD", xReadArgs(make([]interface{}, 0, 2*len(streams)+5), count, block, streams)...))
+}
+
+// XGroupCreate redis命令，id为空时从最新消息开始消费，mkStream为true时stream不存在则创建
+func (mp *myPool) XGroupCreate(key, group, id string, mkStream bool) (ok bool, err error) {
+	if id == "" {
+		id = "$"
+	}
+
+	var res string
+	if mkStream {
+		res, err = String(mp.Do("XGROUP", "CREATE", key, group, id, "MKSTREAM"))
+	} else {
+		res, err = String(mp.Do("XGROUP", "CREATE", key, group, id))
+	}
+	return res == Ok, err
+}
+
+// XGroupDestroy redis命令
+func (mp *myPool) XGroupDestroy(key, group string) (ok bool, err error) {
+	return redigo.Bool(mp.Do("XGROUP", "DESTROY", key, group))
+}
+
+// XGroupSetId redis命令
+func (mp *myPool) XGroupSetId(key, group, id string) (ok bool, err error) {
+	var res string
+	res, err = String(mp.Do("XGROUP", "SETID", key, group, id))
+	return res == Ok, err
+}
+
+// XGroupDelConsumer redis命令，返回该消费者删除前未确认的消息数
+func (mp *myPool) XGroupDelConsumer(key, group, consumer string) (pendingNum int, err error) {
+	return redigo.Int(mp.Do("XGROUP", "DELCONSUMER", key, group, consumer))
+}
+
+// XReadGroup redis命令，streams的id为>时读取新消息，block单位ms，0表示不阻塞
+func (mp *myPool) XReadGroup(group, consumer string, count, block int, noAck bool, streams map[string]string) (streamList []Stream, err error) {
+	ctx := withBlock(mp.getCtx(), blockDuration(block))
+	return Streams(mp.DoCtx(ctx, "XREADGROUP", xReadGroupArgs(group, consumer, count, block, noAck, streams)...))
+}
+
+// XAck redis命令
+func (mp *myPool) XAck(key, group string, ids ...string) (ackNum int, err error) {
+	return redigo.Int(mp.Do("XACK", idArgs([]interface{}{key, group}, ids)...))
+}
+
+// XPending redis命令，返回概要信息
+func (mp *myPool) XPending(key, group string) (pending StreamPending, err error) {
+	return StreamPendingSummary(mp.Do("XPENDING", key, group))
+}
+
+// XPendingExt redis命令，返回明细，minIdle单位ms，大于0时需要redis6.2以上，consumer为空时不过滤消费者
+func (mp *myPool) XPendingExt(key, group string, minIdle int, start, end string, count int, consumer string) (entries []StreamPendingEntry, err error) {
+	return StreamPendingEntries(mp.Do("XPENDING", xPendingExtArgs(key, group, minIdle, start, end, count, consumer)...))
+}
+
+// XClaim redis命令，minIdle单位ms
+func (mp *myPool) XClaim(key, group, consumer string, minIdle int, ids ...string) (messages []StreamMessage, err error) {
+	return StreamMessages(mp.Do("XCLAIM", idArgs([]interface{}{key, group, consumer, minIdle}, ids)...))
+}
+
+// XAutoClaim redis命令，需要redis6.2以上，nextId为0-0时表示已扫描完
+func (mp *myPool) XAutoClaim(key, group, consumer string, minIdle int, start string, count int) (nextId string, messages []StreamMessage, err error) {
+	return StreamAutoClaim(mp.Do("XAUTOCLAIM", key, group, consumer, minIdle, start, "COUNT", count))
+}
+
+// XTrim redis命令
+func (mp *myPool) XTrim(key string, trim StreamTrim) (removeNum int, err error) {
+	return redigo.Int(mp.Do("XTRIM", trim.args([]interface{}{key})...))
+}
+
+// XDel redis命令
+func (mp *myPool) XDel(key string, ids ...string) (delNum int, err error) {
+	return redigo.Int(mp.Do("XDEL", idArgs([]interface{}{key}, ids)...))
+}
+
+// XLen redis命令
+func (mp *myPool) XLen(key string) (length int, err error) {
+	return redigo.Int(mp.Do("XLEN", key))
+}
+
+// XInfoStream redis命令
+func (mp *myPool) XInfoStream(key string) (info StreamInfo, err error) {
+	return StreamDetail(mp.Do("XINFO", "STREAM", key))
+}
+
+// XInfoGroups redis命令
+func (mp *myPool) XInfoGroups(key string) (groups []StreamGroup, err error) {
+	return StreamGroups(mp.Do("XINFO", "GROUPS", key))
+}
+
+// XInfoConsumers redis命令
+func (mp *myPool) XInfoConsumers(key, group string) (consumers []StreamConsumer, err error) {
+	return StreamConsumers(mp.Do("XINFO", "CONSUMERS", key, group))
+}
+
+//endregion
+
+// XAdd redis命令
+func (m *multi) XAdd(key, id string, trim *StreamTrim, fields map[string]interface{}) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XADD", args: xAddArgs(key, id, trim, fields)})
+	return m
+}
+
+// XRange redis命令
+func (m *multi) XRange(key, start, end string, count int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XRANGE", args: xRangeArgs(key, start, end, count)})
+	return m
+}
+
+// XRevRange redis命令
+func (m *multi) XRevRange(key, end, start string, count int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XREVRANGE", args: xRangeArgs(key, end, start, count)})
+	return m
+}
+
+// XRead redis命令，pipeline与事务中不阻塞
+func (m *multi) XRead(count int, streams map[string]string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XREAD", args: xReadArgs(make([]interface{}, 0, 2*len(streams)+3), count, 0, streams)})
+	return m
+}
+
+// XGroupCreate redis命令
+func (m *multi) XGroupCreate(key, group, id string, mkStream bool) Multi {
+	if id == "" {
+		id = "$"
+	}
+
+	args := []interface{}{"CREATE", key, group, id}
+	if mkStream {
+		args = append(args, "MKSTREAM")
+	}
+
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XGROUP", args: args})
+	return m
+}
+
+// XGroupDestroy redis命令
+func (m *multi) XGroupDestroy(key, group string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XGROUP", args: []interface{}{"DESTROY", key, group}})
+	return m
+}
+
+// XGroupSetId redis命令
+func (m *multi) XGroupSetId(key, group, id string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XGROUP", args: []interface{}{"SETID", key, group, id}})
+	return m
+}
+
+// XGroupDelConsumer redis命令
+func (m *multi) XGroupDelConsumer(key, group, consumer string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XGROUP", args: []interface{}{"DELCONSUMER", key, group, consumer}})
+	return m
+}
+
+// XReadGroup redis命令，pipeline与事务中不阻塞
+func (m *multi) XReadGroup(group, consumer string, count int, noAck bool, streams map[string]string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XREADGROUP", args: xReadGroupArgs(group, consumer, count, 0, noAck, streams)})
+	return m
+}
+
+// XAck redis命令
+func (m *multi) XAck(key, group string, ids ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XACK", args: idArgs([]interface{}{key, group}, ids)})
+	return m
+}
+
+// XPending redis命令
+func (m *multi) XPending(key, group string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XPENDING", args: []interface{}{key, group}})
+	return m
+}
+
+// XPendingExt redis命令
+func (m *multi) XPendingExt(key, group string, minIdle int, start, end string, count int, consumer string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XPENDING", args: xPendingExtArgs(key, group, minIdle, start, end, count, consumer)})
+	return m
+}
+
+// XClaim redis命令
+func (m *multi) XClaim(key, group, consumer string, minIdle int, ids ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XCLAIM", args: idArgs([]interface{}{key, group, consumer, minIdle}, ids)})
+	return m
+}
+
+// XAutoClaim redis命令
+func (m *multi) XAutoClaim(key, group, consumer string, minIdle int, start string, count int) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XAUTOCLAIM", args: []interface{}{key, group, consumer, minIdle, start, "COUNT", count}})
+	return m
+}
+
+// XTrim redis命令
+func (m *multi) XTrim(key string, trim StreamTrim) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XTRIM", args: trim.args([]interface{}{key})})
+	return m
+}
+
+// XDel redis命令
+func (m *multi) XDel(key string, ids ...string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XDEL", args: idArgs([]interface{}{key}, ids)})
+	return m
+}
+
+// XLen redis命令
+func (m *multi) XLen(key string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XLEN", args: []interface{}{key}})
+	return m
+}
+
+// XInfoStream redis命令
+func (m *multi) XInfoStream(key string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XINFO", args: []interface{}{"STREAM", key}})
+	return m
+}
+
+// XInfoGroups redis命令
+func (m *multi) XInfoGroups(key string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XINFO", args: []interface{}{"GROUPS", key}})
+	return m
+}
+
+// XInfoConsumers redis命令
+func (m *multi) XInfoConsumers(key, group string) Multi {
+	m.cmdList = append(m.cmdList, Cmd{cmd: "XINFO", args: []interface{}{"CONSUMERS", key, group}})
+	return m
+}
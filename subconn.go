@@ -3,12 +3,14 @@ package gedis
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/zap/zapcore"
 )
 
 const (
@@ -54,29 +56,63 @@ type SubConn interface {
 
 type subConn struct {
 	option Option
+	dial   dialer
 	conn   redigo.Conn
 	mu     sync.Mutex
+	// current 当前连接，master切换时不经过mu直接关闭，使阻塞中的Receive返回并重连
+	current      atomic.Value
+	cancelSwitch func()
+	sentinel     *sentinel
+	logger       *poolLogger
 }
 
 func NewSubConn(option Option) (SubConn, error) {
+	option, err := option.resolveURL()
+	if err != nil {
+		return nil, err
+	}
+
 	sc := &subConn{
 		option: option,
+		// 订阅连接阻塞读取消息，不设置读超时
+		dial:   newDialer(option, 0),
+		logger: newPoolLogger(option),
+	}
+
+	if option.Sentinel.enabled() {
+		sc.sentinel = acquireSentinel(option)
 	}
 
-	err := sc.loadConn()
+	err = sc.loadConn()
 	if err != nil {
+		if sc.sentinel != nil {
+			sc.sentinel.release()
+		}
 		return nil, err
 	}
+
+	if sc.sentinel != nil {
+		sc.cancelSwitch = sc.sentinel.onSwitch(func(addr string) {
+			if conn, ok := sc.current.Load().(redigo.Conn); ok {
+				_ = conn.Close()
+			}
+		})
+	}
 	return sc, nil
 }
 
 func (sc *subConn) loadConn() (err error) {
 	var (
-		addr = fmt.Sprintf("%s:%d", sc.option.Host, sc.option.Port)
+		addr string
 		conn redigo.Conn
 	)
-	conn, err = redigo.Dial("tcp", addr)
 
+	addr, err = sc.option.address()
+	if err != nil {
+		return err
+	}
+
+	conn, err = sc.dial(addr)
 	if err != nil {
 		return err
 	}
@@ -85,10 +121,24 @@ func (sc *subConn) loadConn() (err error) {
 	defer sc.mu.Unlock()
 
 	sc.conn = conn
+	sc.current.Store(conn)
 	return nil
 }
 
+func (sc *subConn) logReconnect(err error) {
+	sc.logger.log(zapcore.ErrorLevel, msgSubReconnectFailed,
+		zaplogger.Addr(sc.option.id()),
+		zaplogger.Error(err),
+	)
+}
+
 func (sc *subConn) Close() error {
+	if sc.cancelSwitch != nil {
+		sc.cancelSwitch()
+		sc.cancelSwitch = nil
+		sc.sentinel.release()
+	}
+
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
@@ -134,11 +184,13 @@ func (sc *subConn) SubscribeChannel(ctx context.Context, size int, channels ...i
 
 					if strings.Contains(msg.Error(), badConnFlag) {
 						if err = sc.loadConn(); err != nil {
+							sc.logReconnect(err)
 							time.Sleep(retryInterval)
 							continue
 						}
 
 						if err = sc.Subscribe(channels...); err != nil {
+							sc.logReconnect(err)
 							time.Sleep(retryInterval)
 						}
 						continue
@@ -191,11 +243,13 @@ func (sc *subConn) PSubscribeChannel(ctx context.Context, size int, channels ...
 
 					if strings.Contains(msg.Error(), badConnFlag) {
 						if err = sc.loadConn(); err != nil {
+							sc.logReconnect(err)
 							time.Sleep(retryInterval)
 							continue
 						}
 
 						if err = sc.PSubscribe(channels...); err != nil {
+							sc.logReconnect(err)
 							time.Sleep(retryInterval)
 						}
 						continue
@@ -0,0 +1,151 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/gedis"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/grpc-boot/gedis/tracing"
+	redacted            = "?"
+)
+
+var (
+	NodeKey     = attribute.Key("db.redis.node")
+	CmdsKey     = attribute.Key("db.redis.cmds")
+	ScriptKey   = attribute.Key("db.redis.script_sha")
+	CmdCountKey = attribute.Key("db.redis.cmd_count")
+)
+
+// Option 链路追踪配置
+type Option struct {
+	//为nil时使用otel全局的TracerProvider
+	TracerProvider trace.TracerProvider
+	//为true时语句中的参数以?代替，避免敏感数据写入链路
+	RedactArgs bool
+	//语句最大长度，0表示不限制
+	MaxStatementLength int
+}
+
+type hook struct {
+	option Option
+	tracer trace.Tracer
+}
+
+// NewHook 实例化链路追踪钩子，每个命令、pipeline、事务与lua脚本各生成一个span，父span取自ctx
+func NewHook(option Option) gedis.Hook {
+	provider := option.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &hook{
+		option: option,
+		tracer: provider.Tracer(instrumentationName),
+	}
+}
+
+// Instrument 为Pool添加链路追踪钩子
+func Instrument(pool gedis.Pool, option Option) {
+	pool.AddHook(NewHook(option))
+}
+
+func (h *hook) Before(ctx context.Context, event *gedis.HookEvent) (context.Context, error) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemRedis,
+		NodeKey.String(event.Id),
+	}
+
+	name := strings.ToUpper(event.Cmd)
+	switch event.Kind {
+	case gedis.HookCmd:
+		attrs = append(attrs,
+			semconv.DBOperationKey.String(name),
+			semconv.DBStatementKey.String(h.statement(event.Cmd, event.Args)),
+		)
+	case gedis.HookScript:
+		attrs = append(attrs,
+			semconv.DBOperationKey.String(name),
+			ScriptKey.String(event.Script.Hash()),
+			semconv.DBStatementKey.String(h.statement(event.Cmd+" "+event.Script.Hash(), event.Args)),
+		)
+	default:
+		name = event.Kind.String()
+		cmds := make([]string, len(event.CmdList))
+		for index, cmd := range event.CmdList {
+			cmds[index] = h.statement(cmd.Name(), cmd.Args())
+		}
+
+		attrs = append(attrs,
+			semconv.DBOperationKey.String(name),
+			CmdCountKey.Int(len(cmds)),
+			CmdsKey.StringSlice(cmds),
+			semconv.DBStatementKey.String(h.truncate(strings.Join(cmds, "\n"))),
+		)
+	}
+
+	ctx, _ = h.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(event.Start),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, nil
+}
+
+func (h *hook) After(ctx context.Context, event *gedis.HookEvent) {
+	span := trace.SpanFromContext(ctx)
+	if event.Err != nil && event.Err != redigo.ErrNil {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+
+	span.End(trace.WithTimestamp(event.Start.Add(event.Duration)))
+}
+
+// statement 拼接命令与参数
+func (h *hook) statement(cmd string, args []interface{}) string {
+	var sb strings.Builder
+	sb.WriteString(cmd)
+
+	for _, arg := range args {
+		sb.WriteByte(' ')
+		if h.option.RedactArgs {
+			sb.WriteString(redacted)
+			continue
+		}
+
+		switch val := arg.(type) {
+		case string:
+			sb.WriteString(val)
+		case []byte:
+			sb.Write(val)
+		case int:
+			sb.WriteString(strconv.Itoa(val))
+		case int64:
+			sb.WriteString(strconv.FormatInt(val, 10))
+		case float64:
+			sb.WriteString(strconv.FormatFloat(val, 'g', -1, 64))
+		default:
+			_, _ = fmt.Fprint(&sb, val)
+		}
+	}
+
+	return h.truncate(sb.String())
+}
+
+func (h *hook) truncate(statement string) string {
+	if h.option.MaxStatementLength > 0 && len(statement) > h.option.MaxStatementLength {
+		return statement[:h.option.MaxStatementLength] + "..."
+	}
+	return statement
+}
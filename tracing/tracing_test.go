@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grpc-boot/gedis"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+)
+
+func TestHook(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	option := gedis.DefaultOption()
+	option.Host = "127.0.0.1"
+	option.Port = 1
+
+	pool := gedis.NewPool(option)
+	Instrument(pool, Option{TracerProvider: provider, RedactArgs: true})
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, _ = pool.DoCtx(ctx, "SET", "key", "secret")
+
+	m := gedis.PipeMulti()
+	m.Get("key")
+	m.Incr("num")
+	_, _ = pool.ExecCtx(ctx, m)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("want 3 spans, got %d", len(spans))
+	}
+
+	cmd, pipeline := spans[0], spans[1]
+	if cmd.Name != "SET" || cmd.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("unexpected cmd span %s parent %s", cmd.Name, cmd.Parent.SpanID())
+	}
+
+	attrs := attribute.NewSet(cmd.Attributes...)
+	if val, _ := attrs.Value(semconv.DBStatementKey); val.AsString() != "SET ? ?" {
+		t.Fatalf("want redacted statement, got %s", val.AsString())
+	}
+
+	if val, _ := attrs.Value(semconv.DBSystemKey); val.AsString() != "redis" {
+		t.Fatalf("want db.system redis, got %s", val.AsString())
+	}
+
+	if val, _ := attrs.Value(NodeKey); val.AsString() != "127.0.0.1:1-0" {
+		t.Fatalf("want node id, got %s", val.AsString())
+	}
+
+	if cmd.Status.Code != codes.Error || len(cmd.Events) == 0 {
+		t.Fatalf("want error recorded, got %v", cmd.Status)
+	}
+
+	attrs = attribute.NewSet(pipeline.Attributes...)
+	if val, _ := attrs.Value(CmdsKey); pipeline.Name != "pipeline" || len(val.AsStringSlice()) != 2 {
+		t.Fatalf("unexpected pipeline span %s %v", pipeline.Name, val.AsStringSlice())
+	}
+}
@@ -52,6 +52,7 @@ func (mp *myPool) GetToken(key string, current int64, capacity, rate, reqNum, ke
 		res int64
 	)
 	res, err = mp.EvalOrSha4Int64(tokenLimitScript, key, capacity, current, rate, reqNum, keyTimeoutSecond)
+	mp.counter.limit(res == 1, err)
 	return res == 1, err
 }
 
@@ -78,6 +79,7 @@ func (mp *myPool) SecondLimitByTime(key string, limit int, reqNum int) (ok bool,
 		_, _ = mp.Expire(key, 10)
 	}
 
+	mp.counter.limit(newVal <= int64(limit), err)
 	return newVal <= int64(limit), err
 }
 
@@ -93,6 +95,7 @@ func (mp *myPool) MinuteLimitByTime(key string, limit int, reqNum int) (ok bool,
 		_, _ = mp.Expire(key, 600)
 	}
 
+	mp.counter.limit(newVal <= int64(limit), err)
 	return newVal <= int64(limit), err
 }
 
@@ -108,6 +111,7 @@ func (mp *myPool) HourLimitByTime(key string, limit int, reqNum int) (ok bool, e
 		_, _ = mp.Expire(key, 3680)
 	}
 
+	mp.counter.limit(newVal <= int64(limit), err)
 	return newVal <= int64(limit), err
 }
 
@@ -123,5 +127,6 @@ func (mp *myPool) DayLimitByTime(key string, limit int, reqNum int) (ok bool, er
 		_, _ = mp.Expire(key, 86400)
 	}
 
+	mp.counter.limit(newVal <= int64(limit), err)
 	return newVal <= int64(limit), err
 }
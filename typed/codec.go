@@ -0,0 +1,80 @@
+package typed
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+
+	"github.com/grpc-boot/gedis"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrNotProtoMessage = gedis.NewError(`codec: value is not proto.Message`)
+)
+
+var (
+	// MsgpackCodec msgpack编码，map按遍历顺序编码，相同的值编码结果可能不同，包含map时不能用作有序集合成员
+	MsgpackCodec gedis.Codec = msgpackCodec{}
+	// GobCodec gob编码，每个值都包含类型信息，适合结构较稳定的数据，map的编码结果同样不确定，包含map时不能用作有序集合成员
+	GobCodec gedis.Codec = gobCodec{}
+	// ProtoCodec protobuf编码，值需为proto.Message
+	ProtoCodec gedis.Codec = protoCodec{}
+)
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+	return proto.Marshal(msg)
+}
+
+// Unmarshal v可以是proto.Message，也可以是指向proto.Message指针的指针，为nil时自动创建
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, msg)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Ptr {
+		return ErrNotProtoMessage
+	}
+
+	if rv.Elem().IsNil() {
+		rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+	}
+
+	msg, ok := rv.Elem().Interface().(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+	return proto.Unmarshal(data, msg)
+}
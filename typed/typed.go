@@ -0,0 +1,353 @@
+package typed
+
+import (
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/gedis"
+)
+
+// typed 类型化操作的公共部分，codec为nil时使用JsonCodec
+type typed[T any] struct {
+	pool  gedis.Pool
+	codec gedis.Codec
+}
+
+func newTyped[T any](pool gedis.Pool, codec gedis.Codec) typed[T] {
+	if codec == nil {
+		codec = gedis.JsonCodec
+	}
+	return typed[T]{pool: pool, codec: codec}
+}
+
+func (t typed[T]) encode(value T) ([]byte, error) {
+	return t.codec.Marshal(value)
+}
+
+func (t typed[T]) encodeList(values []T) ([]interface{}, error) {
+	list := make([]interface{}, len(values))
+	for index, value := range values {
+		data, err := t.encode(value)
+		if err != nil {
+			return nil, err
+		}
+		list[index] = data
+	}
+	return list, nil
+}
+
+func (t typed[T]) decode(data []byte) (value T, err error) {
+	err = t.codec.Unmarshal(data, &value)
+	return
+}
+
+// decodeReply 转换单个回复，nil回复时exists为false
+func (t typed[T]) decodeReply(reply interface{}, err error) (T, bool, error) {
+	data, err := redigo.Bytes(reply, err)
+	if err == redigo.ErrNil {
+		err = nil
+	}
+
+	if err != nil || data == nil {
+		var value T
+		return value, false, err
+	}
+
+	value, err := t.decode(data)
+	return value, err == nil, err
+}
+
+// decodeMap 按names转换列表回复，nil回复被忽略
+func (t typed[T]) decodeMap(names []string, reply interface{}, err error) (map[string]T, error) {
+	list, err := redigo.ByteSlices(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]T, len(list))
+	for index, data := range list {
+		if data == nil || index >= len(names) {
+			continue
+		}
+
+		if values[names[index]], err = t.decode(data); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (t typed[T]) decodeList(reply interface{}, err error) ([]T, error) {
+	list, err := redigo.ByteSlices(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]T, len(list))
+	for index, data := range list {
+		if values[index], err = t.decode(data); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+//region Value
+
+// Value 类型化的字符串值
+type Value[T any] struct {
+	typed[T]
+}
+
+// NewValue 实例化Value，codec为nil时使用JsonCodec
+func NewValue[T any](pool gedis.Pool, codec gedis.Codec) Value[T] {
+	return Value[T]{typed: newTyped[T](pool, codec)}
+}
+
+// Get key不存在时exists为false
+func (v Value[T]) Get(key string) (value T, exists bool, err error) {
+	return v.decodeReply(v.pool.Do("GET", key))
+}
+
+func (v Value[T]) Set(key string, value T, args ...interface{}) (ok bool, err error) {
+	data, err := v.encode(value)
+	if err != nil {
+		return false, err
+	}
+	return v.pool.Set(key, data, args...)
+}
+
+func (v Value[T]) SetEx(key string, seconds int, value T) (ok bool, err error) {
+	data, err := v.encode(value)
+	if err != nil {
+		return false, err
+	}
+	return v.pool.SetEx(key, seconds, data)
+}
+
+// MGet 不存在的key不包含在结果中
+func (v Value[T]) MGet(keys ...string) (values map[string]T, err error) {
+	args := make([]interface{}, len(keys))
+	for index, key := range keys {
+		args[index] = key
+	}
+	reply, err := v.pool.Do("MGET", args...)
+	return v.decodeMap(keys, reply, err)
+}
+
+// CacheGet 通用缓存，handler返回的值编码后缓存
+func (v Value[T]) CacheGet(key string, current, timeoutSecond int64, handler func() (T, error)) (value T, err error) {
+	data, err := v.pool.CacheGet(key, current, timeoutSecond, func() ([]byte, error) {
+		val, err := handler()
+		if err != nil {
+			return nil, err
+		}
+		return v.encode(val)
+	})
+
+	if err != nil || data == nil {
+		return
+	}
+	return v.decode(data)
+}
+
+//endregion
+
+//region HashOf
+
+// HashOf 类型化的hash，field为字符串，value为T
+type HashOf[T any] struct {
+	typed[T]
+}
+
+// NewHashOf 实例化HashOf，codec为nil时使用JsonCodec
+func NewHashOf[T any](pool gedis.Pool, codec gedis.Codec) HashOf[T] {
+	return HashOf[T]{typed: newTyped[T](pool, codec)}
+}
+
+func (h HashOf[T]) HSet(key string, field string, value T) (isNew int, err error) {
+	data, err := h.encode(value)
+	if err != nil {
+		return 0, err
+	}
+	return h.pool.HSet(key, field, data)
+}
+
+// HGet field不存在时exists为false
+func (h HashOf[T]) HGet(key string, field string) (value T, exists bool, err error) {
+	return h.decodeReply(h.pool.Do("HGET", key, field))
+}
+
+func (h HashOf[T]) HMSet(key string, values map[string]T) (ok bool, err error) {
+	if len(values) == 0 {
+		return true, nil
+	}
+
+	args := make([]interface{}, 0, 2*len(values)+1)
+	args = append(args, key)
+	for field, value := range values {
+		data, err := h.encode(value)
+		if err != nil {
+			return false, err
+		}
+		args = append(args, field, data)
+	}
+
+	reply, err := redigo.String(h.pool.Do("HMSET", args...))
+	return reply == gedis.Ok, err
+}
+
+// HMGet 不存在的field不包含在结果中
+func (h HashOf[T]) HMGet(key string, fields ...string) (values map[string]T, err error) {
+	args := make([]interface{}, 0, len(fields)+1)
+	args = append(args, key)
+	for _, field := range fields {
+		args = append(args, field)
+	}
+	reply, err := h.pool.Do("HMGET", args...)
+	return h.decodeMap(fields, reply, err)
+}
+
+func (h HashOf[T]) HGetAll(key string) (values map[string]T, err error) {
+	keyValues, err := h.pool.HGetAllBytes(key)
+	if err != nil {
+		return nil, err
+	}
+
+	values = make(map[string]T, len(keyValues))
+	for field, data := range keyValues {
+		if values[field], err = h.decode(data); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+//endregion
+
+//region ListOf
+
+// ListOf 类型化的list
+type ListOf[T any] struct {
+	typed[T]
+}
+
+// NewListOf 实例化ListOf，codec为nil时使用JsonCodec
+func NewListOf[T any](pool gedis.Pool, codec gedis.Codec) ListOf[T] {
+	return ListOf[T]{typed: newTyped[T](pool, codec)}
+}
+
+func (l ListOf[T]) LPush(key string, values ...T) (listLength int, err error) {
+	list, err := l.encodeList(values)
+	if err != nil {
+		return 0, err
+	}
+	return l.pool.LPush(key, list...)
+}
+
+func (l ListOf[T]) RPush(key string, values ...T) (listLength int, err error) {
+	list, err := l.encodeList(values)
+	if err != nil {
+		return 0, err
+	}
+	return l.pool.RPush(key, list...)
+}
+
+// LPop list为空时exists为false
+func (l ListOf[T]) LPop(key string) (value T, exists bool, err error) {
+	return l.decodeReply(l.pool.Do("LPOP", key))
+}
+
+// RPop list为空时exists为false
+func (l ListOf[T]) RPop(key string) (value T, exists bool, err error) {
+	return l.decodeReply(l.pool.Do("RPOP", key))
+}
+
+func (l ListOf[T]) LRange(key string, start, stop int) (values []T, err error) {
+	return l.decodeList(l.pool.Do("LRANGE", key, start, stop))
+}
+
+//endregion
+
+//region SortedSetOf
+
+// Scored 带分数的有序集合成员
+type Scored[T any] struct {
+	Member T
+	Score  float64
+}
+
+// SortedSetOf 类型化的有序集合，成员为编码后的值，codec需保证相同的值编码结果相同，
+// 成员包含map时不能使用MsgpackCodec与GobCodec
+type SortedSetOf[T any] struct {
+	typed[T]
+}
+
+// NewSortedSetOf 实例化SortedSetOf，codec为nil时使用JsonCodec
+func NewSortedSetOf[T any](pool gedis.Pool, codec gedis.Codec) SortedSetOf[T] {
+	return SortedSetOf[T]{typed: newTyped[T](pool, codec)}
+}
+
+func (s SortedSetOf[T]) ZAdd(key string, score float64, member T, flags ...gedis.ZAddFlag) (num int, err error) {
+	data, err := s.encode(member)
+	if err != nil {
+		return 0, err
+	}
+	return s.pool.ZAddMembers(key, []gedis.ZMember{{Member: string(data), Score: score}}, flags...)
+}
+
+func (s SortedSetOf[T]) ZIncrBy(key string, increment float64, member T) (newScore float64, err error) {
+	data, err := s.encode(member)
+	if err != nil {
+		return 0, err
+	}
+	return s.pool.ZIncrByFloat(key, increment, string(data))
+}
+
+// ZScore 成员不存在时exists为false
+func (s SortedSetOf[T]) ZScore(key string, member T) (score float64, exists bool, err error) {
+	data, err := s.encode(member)
+	if err != nil {
+		return 0, false, err
+	}
+	return s.pool.ZScoreFloat(key, string(data))
+}
+
+func (s SortedSetOf[T]) ZRem(key string, members ...T) (removeNum int, err error) {
+	list, err := s.encodeList(members)
+	if err != nil {
+		return 0, err
+	}
+	return s.pool.ZRem(key, list...)
+}
+
+func (s SortedSetOf[T]) ZRangeWithScores(key string, startIndex, stopIndex int) (members []Scored[T], err error) {
+	return s.decodeMembers(s.pool.ZRangeWithScores(key, startIndex, stopIndex))
+}
+
+func (s SortedSetOf[T]) ZRevRangeWithScores(key string, startIndex, stopIndex int) (members []Scored[T], err error) {
+	return s.decodeMembers(s.pool.ZRevRangeWithScores(key, startIndex, stopIndex))
+}
+
+func (s SortedSetOf[T]) ZRangeByScoreWithScores(key string, minScore, maxScore interface{}, offset, limit int) (members []Scored[T], err error) {
+	return s.decodeMembers(s.pool.ZRangeByScoreWithScores(key, minScore, maxScore, offset, limit))
+}
+
+func (s SortedSetOf[T]) ZRevRangeByScoreWithScores(key string, maxScore, minScore interface{}, offset, limit int) (members []Scored[T], err error) {
+	return s.decodeMembers(s.pool.ZRevRangeByScoreWithScores(key, maxScore, minScore, offset, limit))
+}
+
+func (s SortedSetOf[T]) decodeMembers(list []gedis.ZMember, err error) ([]Scored[T], error) {
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]Scored[T], len(list))
+	for index, m := range list {
+		if members[index].Member, err = s.decode([]byte(m.Member)); err != nil {
+			return nil, err
+		}
+		members[index].Score = m.Score
+	}
+	return members, nil
+}
+
+//endregion
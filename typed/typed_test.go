@@ -0,0 +1,145 @@
+package typed
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/grpc-boot/gedis"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// fakePool 记录命令并按命令返回replies中的回复，未实现的方法会panic
+type fakePool struct {
+	gedis.Pool
+
+	cmds    []string
+	replies map[string]func(args []interface{}) interface{}
+}
+
+func (fp *fakePool) Do(cmd string, args ...interface{}) (reply interface{}, err error) {
+	parts := []string{cmd}
+	for _, arg := range args {
+		if data, ok := arg.([]byte); ok {
+			arg = string(data)
+		}
+		parts = append(parts, fmt.Sprint(arg))
+	}
+	fp.cmds = append(fp.cmds, strings.Join(parts, " "))
+
+	if handler, ok := fp.replies[cmd]; ok {
+		return handler(args), nil
+	}
+	return int64(1), nil
+}
+
+func (fp *fakePool) Set(key string, value interface{}, args ...interface{}) (ok bool, err error) {
+	reply, err := redigo.String(fp.Do("SET", append([]interface{}{key, value}, args...)...))
+	return reply == gedis.Ok, err
+}
+
+func (fp *fakePool) RPush(key string, values ...interface{}) (listLength int, err error) {
+	return redigo.Int(fp.Do("RPUSH", append([]interface{}{key}, values...)...))
+}
+
+func (fp *fakePool) ZRevRangeWithScores(key string, startIndex, stopIndex int) (members []gedis.ZMember, err error) {
+	return gedis.ZMembers(fp.Do("ZREVRANGE", key, startIndex, stopIndex, "WITHSCORES"))
+}
+
+func TestCodec(t *testing.T) {
+	type user struct {
+		Id   int64
+		Name string
+		Tags map[string]string
+	}
+
+	u := user{Id: 1, Name: "gedis", Tags: map[string]string{"b": "2", "a": "1"}}
+	for name, codec := range map[string]gedis.Codec{"json": gedis.JsonCodec, "msgpack": MsgpackCodec, "gob": GobCodec} {
+		data, err := codec.Marshal(u)
+		if err != nil {
+			t.Fatalf("%s marshal failed: %v", name, err)
+		}
+
+		var got user
+		if err = codec.Unmarshal(data, &got); err != nil || !reflect.DeepEqual(got, u) {
+			t.Fatalf("%s want %v, got %v %v", name, u, got, err)
+		}
+	}
+
+	if _, err := ProtoCodec.Marshal(u); !errors.Is(err, ErrNotProtoMessage) {
+		t.Fatalf("want ErrNotProtoMessage, got %v", err)
+	}
+
+	data, err := ProtoCodec.Marshal(wrapperspb.String("gedis"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 解码到nil指针时自动创建消息
+	var msg *wrapperspb.StringValue
+	if err = ProtoCodec.Unmarshal(data, &msg); err != nil || msg.GetValue() != "gedis" {
+		t.Fatalf("want gedis, got %v %v", msg, err)
+	}
+}
+
+func TestTyped(t *testing.T) {
+	type user struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+
+	p := &fakePool{replies: map[string]func(args []interface{}) interface{}{
+		"GET": func(args []interface{}) interface{} {
+			if args[0] == "user:1" {
+				return []byte(`{"id":1,"name":"a"}`)
+			}
+			return nil
+		},
+		"SET": func(args []interface{}) interface{} {
+			return gedis.Ok
+		},
+		"HMGET": func(args []interface{}) interface{} {
+			return []interface{}{[]byte(`{"id":2,"name":"b"}`), nil}
+		},
+		"ZREVRANGE": func(args []interface{}) interface{} {
+			return []interface{}{[]byte(`{"id":2,"name":"b"}`), []byte("20"), []byte(`{"id":1,"name":"a"}`), []byte("10")}
+		},
+	}}
+
+	users := NewValue[user](p, nil)
+	if ok, err := users.Set("user:1", user{Id: 1, Name: "a"}, "EX", 60); err != nil || !ok {
+		t.Fatalf("want ok, got %v %v", ok, err)
+	}
+
+	if u, exists, err := users.Get("user:1"); err != nil || !exists || u != (user{Id: 1, Name: "a"}) {
+		t.Fatalf("unexpected user %v %v %v", u, exists, err)
+	}
+
+	if _, exists, err := users.Get("user:3"); err != nil || exists {
+		t.Fatalf("want not exists, got %v %v", exists, err)
+	}
+
+	values, err := NewHashOf[user](p, gedis.JsonCodec).HMGet("users", "2", "3")
+	if err != nil || len(values) != 1 || values["2"] != (user{Id: 2, Name: "b"}) {
+		t.Fatalf("unexpected values %v %v", values, err)
+	}
+
+	if length, err := NewListOf[user](p, nil).RPush("list", user{Id: 1}, user{Id: 2}); err != nil || length != 1 {
+		t.Fatalf("want 1, got %d %v", length, err)
+	}
+
+	members, err := NewSortedSetOf[user](p, nil).ZRevRangeWithScores("rank", 0, -1)
+	if err != nil || len(members) != 2 || members[0].Member.Id != 2 || members[1].Score != 10 {
+		t.Fatalf("unexpected members %v %v", members, err)
+	}
+
+	joined := strings.Join(p.cmds, "\n")
+	for _, cmd := range []string{`SET user:1 {"id":1,"name":"a"} EX 60`, `RPUSH list {"id":1,"name":""} {"id":2,"name":""}`} {
+		if !strings.Contains(joined, cmd) {
+			t.Fatalf("want %s in %s", cmd, joined)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package gedis
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	urlTimeoutParam = `timeout`
+	urlParam        = `url`
+	defaultPort     = 6379
+)
+
+var (
+	ErrURLScheme = NewError(`url: scheme must be redis, rediss or unix`)
+	ErrURLParam  = NewError(`url: invalid param`)
+)
+
+// ParseURL 解析连接地址，格式: redis[s]://[[username][:password]@]host[:port][/db][?param=value]，
+// unix socket格式: unix://[[username][:password]@]/path/to/redis.sock[?db=0&param=value]
+// 参数名与Option的json标签一致，嵌套配置使用"."连接，如sentinel.masterName，切片使用","分隔；
+// 单位为毫秒或秒的参数同时支持300ms、10s等时长写法，timeout同时设置连接、读、写超时
+func ParseURL(rawURL string) (option Option, err error) {
+	option = DefaultOption()
+	err = option.applyURL(rawURL)
+	return option, err
+}
+
+// resolveURL 配置了URL时返回解析URL后的配置
+func (o Option) resolveURL() (Option, error) {
+	if o.URL == "" {
+		return o, nil
+	}
+
+	rawURL := o.URL
+	o.URL = ""
+	err := o.applyURL(rawURL)
+	return o, err
+}
+
+// applyURL 将连接地址与参数写入option
+func (o *Option) applyURL(rawURL string) (err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case "redis", "rediss":
+		o.TLS = u.Scheme == "rediss"
+		if o.Network == NetworkUnix {
+			o.Network = NetworkTcp
+		}
+	case "unix":
+		o.Network = NetworkUnix
+	default:
+		return ErrURLScheme
+	}
+
+	if o.Network == NetworkUnix {
+		if u.Path == "" {
+			return fmt.Errorf("%w: socket path is empty", ErrURLParam)
+		}
+		o.Host = u.Path
+	} else if err = parseHost(u, o); err != nil {
+		return err
+	}
+
+	// user:pass@设置用户名与密码，:pass@只设置密码，user@只设置用户名
+	if u.User != nil {
+		o.Username = u.User.Username()
+		if password, hasPassword := u.User.Password(); hasPassword {
+			o.Auth = password
+		}
+	}
+
+	query := u.Query()
+	if timeout := query.Get(urlTimeoutParam); timeout != "" {
+		ms, er := parseNumber(timeout, time.Millisecond)
+		if er != nil {
+			return fmt.Errorf("%w: %s=%s", ErrURLParam, urlTimeoutParam, timeout)
+		}
+		o.ConnectTimeout, o.ReadTimeout, o.WriteTimeout = int(ms), int(ms), int(ms)
+		query.Del(urlTimeoutParam)
+	}
+
+	value := reflect.ValueOf(o).Elem()
+	for name, values := range query {
+		field, ok := fieldByTag(value, name)
+		if !ok || name == urlParam {
+			return fmt.Errorf("%w: unknown param %s", ErrURLParam, name)
+		}
+
+		if err = setField(field, name, values[len(values)-1]); err != nil {
+			return fmt.Errorf("%w: %s=%s", ErrURLParam, name, values[len(values)-1])
+		}
+	}
+
+	return nil
+}
+
+// parseHost 解析tcp地址与db
+func parseHost(u *url.URL, option *Option) (err error) {
+	if host := u.Hostname(); host != "" {
+		option.Host = host
+	}
+
+	option.Port = defaultPort
+	if port := u.Port(); port != "" {
+		if option.Port, err = strconv.Atoi(port); err != nil {
+			return fmt.Errorf("%w: port %s", ErrURLParam, port)
+		}
+	}
+
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		n, err := strconv.ParseUint(db, 10, 8)
+		if err != nil {
+			return fmt.Errorf("%w: db %s", ErrURLParam, db)
+		}
+		option.Db = uint8(n)
+	}
+	return nil
+}
+
+// NewPoolWithURL 通过连接地址实例化Pool
+func NewPoolWithURL(rawURL string) (p Pool, err error) {
+	option, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPool(option), nil
+}
+
+// fieldByTag 按json标签查找字段，嵌套结构体使用"."连接
+func fieldByTag(value reflect.Value, name string) (field reflect.Value, ok bool) {
+	tagName, rest := name, ""
+	if index := strings.IndexByte(name, '.'); index > -1 {
+		tagName, rest = name[:index], name[index+1:]
+	}
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" || tag != tagName {
+			continue
+		}
+
+		field = value.Field(i)
+		if rest == "" {
+			return field, field.Kind() != reflect.Struct
+		}
+
+		if field.Kind() != reflect.Struct {
+			return field, false
+		}
+		return fieldByTag(field, rest)
+	}
+
+	return field, false
+}
+
+func setField(field reflect.Value, name, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		unit := time.Millisecond
+		if strings.HasSuffix(name, "Second") {
+			unit = time.Second
+		}
+
+		n, err := parseNumber(value, unit)
+		if err != nil {
+			return err
+		}
+
+		if field.OverflowInt(n) {
+			return strconv.ErrRange
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		if field.OverflowUint(n) {
+			return strconv.ErrRange
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return ErrURLParam
+		}
+
+		parts := strings.Split(value, ",")
+		field.Set(reflect.ValueOf(parts))
+	default:
+		return ErrURLParam
+	}
+
+	return nil
+}
+
+// parseNumber 解析整数，非整数时按时长解析并换算为unit的个数
+func parseNumber(value string, unit time.Duration) (int64, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		return n, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	return int64(d / unit), nil
+}
@@ -0,0 +1,311 @@
+package gedis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultWorkerCount         = 10
+	defaultWorkerBlock         = 2000
+	defaultWorkerClaimIdle     = 60000
+	defaultWorkerClaimInterval = 30000
+	defaultWorkerMaxDeliveries = 5
+	workerRetryInterval        = time.Second
+)
+
+// 死信消息附加的字段
+const (
+	DeadLetterStream     = `_stream`
+	DeadLetterId         = `_id`
+	DeadLetterDeliveries = `_deliveries`
+)
+
+// WorkerHandler 消息处理函数，返回nil时确认消息，否则消息保留在待确认列表中等待重新投递
+type WorkerHandler func(ctx context.Context, msg StreamMessage) error
+
+// WorkerOption 消费组worker配置
+type WorkerOption struct {
+	Stream string `yaml:"stream" json:"stream"`
+	Group  string `yaml:"group" json:"group"`
+	//消费者名称，默认为hostname-pid
+	Consumer string `yaml:"consumer" json:"consumer"`
+	//消费组不存在时创建消费组的起始id，默认$
+	StartId string `yaml:"startId" json:"startId"`
+	//消费协程数，默认1
+	Concurrency int `yaml:"concurrency" json:"concurrency"`
+	//单次读取的消息数，默认10
+	Count int `yaml:"count" json:"count"`
+	//XREADGROUP阻塞时长，单位ms，默认2000
+	Block int `yaml:"block" json:"block"`
+	//消息空闲超过该时长后被重新认领，需大于消息的最长处理时长，单位ms，默认60000
+	ClaimIdle int `yaml:"claimIdle" json:"claimIdle"`
+	//认领间隔，单位ms，默认30000
+	ClaimInterval int `yaml:"claimInterval" json:"claimInterval"`
+	//最大投递次数，超过后移入死信stream，默认5
+	MaxDeliveries int `yaml:"maxDeliveries" json:"maxDeliveries"`
+	//死信stream，默认为Stream:dead
+	DeadLetter string `yaml:"deadLetter" json:"deadLetter"`
+	//为nil时使用包级别的Debug与Error
+	Logger Logger `yaml:"-" json:"-"`
+}
+
+// Worker 消费组worker
+type Worker interface {
+	// Run 阻塞消费消息，ctx取消后停止读取并等待处理中的消息结束
+	Run(ctx context.Context) error
+}
+
+type worker struct {
+	pool    Pool
+	option  WorkerOption
+	handler WorkerHandler
+	logger  Logger
+}
+
+// NewWorker 实例化Worker，认领依赖XAUTOCLAIM，需要redis6.2以上
+func NewWorker(pool Pool, option WorkerOption, handler WorkerHandler) Worker {
+	if option.Consumer == "" {
+		hostname, _ := os.Hostname()
+		option.Consumer = hostname + "-" + strconv.Itoa(os.Getpid())
+	}
+
+	if option.Concurrency < 1 {
+		option.Concurrency = 1
+	}
+
+	if option.Count < 1 {
+		option.Count = defaultWorkerCount
+	}
+
+	if option.Block < 1 {
+		option.Block = defaultWorkerBlock
+	}
+
+	if option.ClaimIdle < 1 {
+		option.ClaimIdle = defaultWorkerClaimIdle
+	}
+
+	if option.ClaimInterval < 1 {
+		option.ClaimInterval = defaultWorkerClaimInterval
+	}
+
+	if option.MaxDeliveries < 1 {
+		option.MaxDeliveries = defaultWorkerMaxDeliveries
+	}
+
+	if option.DeadLetter == "" {
+		option.DeadLetter = option.Stream + ":dead"
+	}
+
+	w := &worker{
+		pool:    pool,
+		option:  option,
+		handler: handler,
+		logger:  option.Logger,
+	}
+
+	if w.logger == nil {
+		w.logger = defaultLogger{}
+	}
+	return w
+}
+
+func (w *worker) Run(ctx context.Context) error {
+	_, err := w.pool.XGroupCreate(w.option.Stream, w.option.Group, w.option.StartId, true)
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(w.option.Concurrency + 1)
+	for i := 0; i < w.option.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			w.consume(ctx)
+		}()
+	}
+
+	go func() {
+		defer wg.Done()
+		w.reclaim(ctx)
+	}()
+
+	wg.Wait()
+	return nil
+}
+
+// consume 读取新消息并处理
+func (w *worker) consume(ctx context.Context) {
+	var (
+		pool    = w.pool.WithContext(ctx)
+		streams = map[string]string{w.option.Stream: ">"}
+	)
+
+	for ctx.Err() == nil {
+		streamList, err := pool.XReadGroup(w.option.Group, w.option.Consumer, w.option.Count, w.option.Block, false, streams)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			w.logger.Error(msgWorkerReadFailed, w.fields(zaplogger.Error(err))...)
+			w.sleep(ctx, workerRetryInterval)
+			continue
+		}
+
+		for _, stream := range streamList {
+			for _, msg := range stream.Messages {
+				if ctx.Err() != nil {
+					return
+				}
+				w.handle(ctx, msg)
+			}
+		}
+	}
+}
+
+// reclaim 定时认领空闲超时的消息
+func (w *worker) reclaim(ctx context.Context) {
+	ticker := time.NewTicker(time.Millisecond * time.Duration(w.option.ClaimInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.claim(ctx)
+		}
+	}
+}
+
+// claim 通过XAUTOCLAIM遍历待确认列表，超过最大投递次数的消息移入死信，其余重新处理
+func (w *worker) claim(ctx context.Context) {
+	start := "0-0"
+	for ctx.Err() == nil {
+		next, messages, err := w.pool.XAutoClaim(w.option.Stream, w.option.Group, w.option.Consumer, w.option.ClaimIdle, start, w.option.Count)
+		if err != nil {
+			w.logger.Error(msgWorkerReadFailed, w.fields(zaplogger.Error(err))...)
+			return
+		}
+
+		if len(messages) > 0 {
+			w.redeliver(ctx, messages)
+		}
+
+		if next == "0-0" || next == "" {
+			return
+		}
+		start = next
+	}
+}
+
+// redeliver 逐条查询投递次数，范围查询时同一区间内的其它待确认消息会占用COUNT
+func (w *worker) redeliver(ctx context.Context, messages []StreamMessage) {
+	m := PipeMulti()
+	for _, msg := range messages {
+		m.XPendingExt(w.option.Stream, w.option.Group, 0, msg.ID, msg.ID, 1, w.option.Consumer)
+	}
+
+	values, err := w.pool.Exec(m)
+	if err != nil {
+		w.logger.Error(msgWorkerReadFailed, w.fields(zaplogger.Error(err))...)
+		return
+	}
+
+	deliveries := make(map[string]int64, len(messages))
+	for _, value := range values {
+		entries, err := StreamPendingEntries(value, nil)
+		if err != nil {
+			w.logger.Error(msgWorkerReadFailed, w.fields(zaplogger.Error(err))...)
+			return
+		}
+
+		for _, entry := range entries {
+			deliveries[entry.ID] = entry.DeliveryCount
+		}
+	}
+
+	for _, msg := range messages {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if count := deliveries[msg.ID]; count > int64(w.option.MaxDeliveries) {
+			w.deadLetter(msg, count)
+			continue
+		}
+		w.handle(ctx, msg)
+	}
+}
+
+// deadLetter 写入死信stream后确认原消息，确认失败时消息可能被重复写入死信
+func (w *worker) deadLetter(msg StreamMessage, deliveries int64) {
+	fields := make(map[string]interface{}, len(msg.Fields)+3)
+	for field, value := range msg.Fields {
+		fields[field] = value
+	}
+	fields[DeadLetterStream] = w.option.Stream
+	fields[DeadLetterId] = msg.ID
+	fields[DeadLetterDeliveries] = deliveries
+
+	if _, err := w.pool.XAdd(w.option.DeadLetter, "", nil, fields); err != nil {
+		w.logger.Error(msgWorkerDeadLetter, w.fields(zaplogger.Error(err), zaplogger.String("Id", msg.ID))...)
+		return
+	}
+
+	w.logger.Warn(msgWorkerDeadLetter, w.fields(zaplogger.String("Id", msg.ID), zaplogger.Int64("Deliveries", deliveries))...)
+	w.ack(msg)
+}
+
+func (w *worker) handle(ctx context.Context, msg StreamMessage) {
+	if err := w.call(ctx, msg); err != nil {
+		w.logger.Error(msgWorkerHandleFailed, w.fields(zaplogger.Error(err), zaplogger.String("Id", msg.ID))...)
+		return
+	}
+	w.ack(msg)
+}
+
+// call 执行handler，panic视为处理失败
+func (w *worker) call(ctx context.Context, msg StreamMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return w.handler(ctx, msg)
+}
+
+func (w *worker) ack(msg StreamMessage) {
+	if _, err := w.pool.XAck(w.option.Stream, w.option.Group, msg.ID); err != nil {
+		w.logger.Error(msgWorkerAckFailed, w.fields(zaplogger.Error(err), zaplogger.String("Id", msg.ID))...)
+	}
+}
+
+func (w *worker) sleep(ctx context.Context, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+func (w *worker) fields(fields ...zap.Field) []zap.Field {
+	return append(fields,
+		zaplogger.Key(w.option.Stream),
+		zaplogger.String("Group", w.option.Group),
+		zaplogger.String("Consumer", w.option.Consumer),
+	)
+}
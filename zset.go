@@ -0,0 +1,80 @@
+package gedis
+
+// ZMember 有序集合成员
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// ZAddFlag ZADD选项
+type ZAddFlag string
+
+const (
+	ZAddNx ZAddFlag = `NX`
+	ZAddXx ZAddFlag = `XX`
+	ZAddGt ZAddFlag = `GT`
+	ZAddLt ZAddFlag = `LT`
+	ZAddCh ZAddFlag = `CH`
+)
+
+const (
+	AggregateSum = `SUM`
+	AggregateMin = `MIN`
+	AggregateMax = `MAX`
+)
+
+func zAddArgs(key string, flags []ZAddFlag, incr bool, members []ZMember) []interface{} {
+	args := make([]interface{}, 0, len(flags)+2*len(members)+2)
+	args = append(args, key)
+	for _, flag := range flags {
+		args = append(args, string(flag))
+	}
+
+	if incr {
+		args = append(args, "INCR")
+	}
+
+	for _, m := range members {
+		args = append(args, m.Score, m.Member)
+	}
+	return args
+}
+
+func zLexArgs(key, min, max string, offset, limit int) []interface{} {
+	if limit == 0 {
+		return []interface{}{key, min, max}
+	}
+	return []interface{}{key, min, max, "LIMIT", offset, limit}
+}
+
+// zStoreArgs ZUNIONSTORE、ZINTERSTORE参数，weights为空时不指定，aggregate为空时使用SUM
+func zStoreArgs(destination string, keys []string, weights []float64, aggregate string) []interface{} {
+	args := make([]interface{}, 0, 2*len(keys)+5)
+	if destination != "" {
+		args = append(args, destination)
+	}
+
+	args = append(args, len(keys))
+	for _, key := range keys {
+		args = append(args, key)
+	}
+
+	if len(weights) > 0 {
+		args = append(args, "WEIGHTS")
+		for _, weight := range weights {
+			args = append(args, weight)
+		}
+	}
+
+	if aggregate != "" {
+		args = append(args, "AGGREGATE", aggregate)
+	}
+	return args
+}
+
+func zPopArgs(key string, count int) []interface{} {
+	if count > 1 {
+		return []interface{}{key, count}
+	}
+	return []interface{}{key}
+}